@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"math"
 
@@ -12,25 +13,30 @@ import (
 
 // VOPLToGLB takes a .vopl file bytes and returns a .glb bytes using greedy mesh
 func VOPLToGLB(voplBytes []byte) ([]byte, error) {
+	return VOPLToGLBWithOptions(voplBytes, nil)
+}
+
+// VOPLToGLBWithOptions is VOPLToGLB with optional baked lighting: when light is non-nil,
+// vertex colors are darkened by the flood-fill light computed with it (see vopl.ComputeLight).
+func VOPLToGLBWithOptions(voplBytes []byte, light *vopl.LightOptions) ([]byte, error) {
 	grid, err := vopl.LoadVoplGridFromBytes(voplBytes)
 	if err != nil {
 		return nil, err
 	}
-	mesh := vopl.GenerateMesh(grid)
+	var mesh *vopl.Mesh
+	if light != nil {
+		mesh = vopl.GenerateLitMesh(grid, vopl.ComputeLight(grid, *light))
+	} else {
+		mesh = vopl.GenerateMesh(grid)
+	}
 
 	positions := make([][3]float32, len(mesh.Vertices))
-	colors := make([][4]float32, len(mesh.Vertices))
-	hasAlpha := false
 	for i, v := range mesh.Vertices {
 		positions[i] = v.Position
-		rgba, err := vopl.ParseHexColor(vopl.Palette[v.Color])
-		if err != nil {
-			return nil, err
-		}
-		colors[i] = rgba
-		if rgba[3] < 1.0 {
-			hasAlpha = true
-		}
+	}
+	colors, hasAlpha, err := mesh.VertexColors()
+	if err != nil {
+		return nil, err
 	}
 	indices := make([]uint32, len(mesh.Indices))
 	copy(indices, mesh.Indices)
@@ -156,3 +162,40 @@ func UnpackVOPLPACKToMemory(packBytes []byte) (map[string][]byte, error) {
 }
 
 // end of file
+
+// ParseLightOptionsJSON decodes the lighting options object accepted by the wasm
+// vopl2glb export, { "sky": 0..15, "emissive": { "<index>": level } }, on top of
+// vopl.DefaultLightOptions. Levels are clamped to 0..vopl.MaxLight, a non-numeric sky
+// is ignored and emissive keys that are not palette indices 1..255 are skipped; an
+// emissive level that is not a number is an error.
+func ParseLightOptionsJSON(data []byte) (*vopl.LightOptions, error) {
+	var raw struct {
+		Sky      any `json:"sky"`
+		Emissive any `json:"emissive"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid light options: %w", err)
+	}
+	level := func(v float64) uint8 {
+		return uint8(math.Min(math.Max(v, 0), vopl.MaxLight))
+	}
+	opts := vopl.DefaultLightOptions()
+	if sky, ok := raw.Sky.(float64); ok {
+		opts.Sky = level(sky)
+	}
+	if em, ok := raw.Emissive.(map[string]any); ok {
+		opts.Emissive = map[uint8]uint8{}
+		for k, v := range em {
+			var idx int
+			if _, err := fmt.Sscan(k, &idx); err != nil || idx < 1 || idx > 255 {
+				continue
+			}
+			n, ok := v.(float64)
+			if !ok {
+				return nil, fmt.Errorf("emissive level for %q must be a number, got %v", k, v)
+			}
+			opts.Emissive[uint8(idx)] = level(n)
+		}
+	}
+	return &opts, nil
+}
@@ -7,18 +7,44 @@ import (
 	"os"
 
 	"github.com/voxelsplace/vopl/go/utils"
+	"github.com/voxelsplace/vopl/go/vopl"
 )
 
 func usage() {
 	fmt.Println("Usage: vopltool <command> [args]")
 	fmt.Println("Commands:")
-	fmt.Println("  updatevopl input.vopl updates.json output.vopl  (apply JSON diff updates)")
+	fmt.Println("  updatevopl input.vopl|world_dir|world.voplpack updates.json output.vopl|output_dir|output.voplpack  (apply JSON diff updates; worlds take every chunk id)")
 	fmt.Println("  vopl2glb input.vopl output.glb         (convert .vopl -> .glb using greedy mesh)")
+	fmt.Println("  vopl2glb input.vopl output.glb light [sky=n] [emissive=i[:level],...]  (bake flood-fill lighting into vertex colors)")
 	fmt.Println("  voplpack2glb input.voplpack output.glb (convert .voplpack -> .glb, one node per entry)")
 	fmt.Println("  vopl2voplpack output.voplpack input1.vopl [input2.vopl ...]   (pack multiple .vopl into a .voplpack)")
 	fmt.Println("  voplpack2vopl input.voplpack output_dir  (unpack .voplpack into directory of .vopl files)")
 	fmt.Println("  gennoise <percentage> <amount> <output_dir>                         (generate N random .vopl chunks with fixed fill %)")
 	fmt.Println("  gennoise <percentageMin> <percentageMax> <amount> <output_dir>     (generate with per-file random fill in [min,max])")
+	fmt.Println("  transform input.vopl output.vopl op [op ...]  (ops: rotx|roty|rotz[:turns], mirrorx|mirrory|mirrorz, shift:dx,dy,dz[,wrap])")
+	fmt.Println("  csg union|intersect|subtract|xor a.vopl b.vopl output.vopl [offset=dx,dy,dz] [overlap=a|b|<index>]")
+	fmt.Println("  stats input.vopl|input_dir|input.voplpack [json|csv]  (print per-chunk statistics to stdout)")
+	fmt.Println("  diff a.vopl b.vopl [output.json]      (updates JSON turning a into b; chunk id from b's name; stdout if no output)")
+	fmt.Println("  hollow input.vopl output.vopl [thickness]  (remove interior voxels invisible from outside; default thickness 1)")
+	fmt.Println("  lod input.vopl output_dir|output.voplpack [majority|visible]  (write LOD levels 16³..1³; default majority)")
+	fmt.Println("  upscale input.vopl factor output_dir|output.voplpack [cx,cy,cz]  (nearest-neighbor upscale split into <chunkId>.vopl chunks)")
+	fmt.Println("  sdf input.vopl output.raw|output.ktx [supersample]  (signed distance field as float32 raw or KTX 3D texture)")
+	fmt.Println("  eval 'expr' output.vopl [cx,cy,cz]   (fill a chunk from a formula, e.g. 'x*x + z*z < 36 && y < 8 ? 12 : 0')")
+	fmt.Println("  eval 'expr' output_dir|output.voplpack x0,y0,z0 x1,y1,z1  (fill an inclusive world region, split into <chunkId>.vopl chunks)")
+	fmt.Println("  text 'string' output.vopl|output_dir|output.voplpack [at=x,y,z] [plane=x,y] [depth=n] [color=n] [scale=n] [wrap=n]  (render bitmap text)")
+	fmt.Println("  copy world_dir|world.voplpack x0,y0,z0 x1,y1,z1 output.voplfab [anchor=x,y,z]  (save an inclusive world region as a prefab; anchor relative to x0,y0,z0)")
+	fmt.Println("  paste input.voplfab world_dir|world.voplpack x,y,z [transparent] [op ...]  (paste a prefab with its anchor at x,y,z; ops: rotx|roty|rotz[:turns], mirrorx|mirrory|mirrorz)")
+	fmt.Println("  find template.vopl|template.voplfab input_dir|input.voplpack [rotate] [mirror] [air=any|empty] [wildcard=n]  (print template matches as JSON)")
+	fmt.Println("  similar input_dir|input.voplpack [threshold]  (print clusters of near-duplicate chunks as JSON; default threshold 0.9)")
+	fmt.Println("  path input.vopl|world_dir|world.voplpack sx,sy,sz gx,gy,gz [headroom=n] [step=n] [drop=n] [margin=n]  (print a walking path of feet cells as JSON)")
+	fmt.Println("  navmesh input.vopl|world_dir|world.voplpack x0,y0,z0 x1,y1,z1 [headroom=n] [step=n] [drop=n]  (print linked walkable regions as JSON)")
+}
+
+// fail reports err on stderr, keeping stdout clean for commands that print JSON or
+// CSV, and exits with status 1.
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "Error:", err)
+	os.Exit(1)
 }
 
 func main() {
@@ -35,21 +61,22 @@ func main() {
 		}
 		updates, err := os.ReadFile(os.Args[3])
 		if err != nil {
-			fmt.Println("Error:", err)
-			os.Exit(1)
+			fail(err)
 		}
 		if err := utils.RunUpdateVOPL(updates, os.Args[2], os.Args[4]); err != nil {
-			fmt.Println("Error:", err)
-			os.Exit(1)
+			fail(err)
 		}
 	case "vopl2glb":
-		if len(os.Args) != 4 {
+		if len(os.Args) < 4 {
 			usage()
 			os.Exit(1)
 		}
-		if err := utils.RunVOPL2GLB(os.Args[2], os.Args[3]); err != nil {
-			fmt.Println("Error:", err)
-			os.Exit(1)
+		light, err := utils.ParseLightOptions(os.Args[4:])
+		if err != nil {
+			fail(err)
+		}
+		if err := utils.RunVOPL2GLBWithOptions(os.Args[2], os.Args[3], light); err != nil {
+			fail(err)
 		}
 	case "voplpack2glb":
 		if len(os.Args) != 4 {
@@ -57,8 +84,7 @@ func main() {
 			os.Exit(1)
 		}
 		if err := utils.RunVOPLPACK2GLB(os.Args[2], os.Args[3]); err != nil {
-			fmt.Println("Error:", err)
-			os.Exit(1)
+			fail(err)
 		}
 	case "vopl2voplpack":
 		if len(os.Args) < 4 {
@@ -68,8 +94,7 @@ func main() {
 		output := os.Args[2]
 		inputs := os.Args[3:]
 		if err := utils.CreatePack(inputs, output); err != nil {
-			fmt.Println("Error:", err)
-			os.Exit(1)
+			fail(err)
 		}
 	case "voplpack2vopl":
 		if len(os.Args) != 4 {
@@ -77,8 +102,7 @@ func main() {
 			os.Exit(1)
 		}
 		if err := utils.RunVOPLPACK2VOPL(os.Args[2], os.Args[3]); err != nil {
-			fmt.Println("Error:", err)
-			os.Exit(1)
+			fail(err)
 		}
 	case "gennoise":
 		// Two forms:
@@ -88,40 +112,290 @@ func main() {
 			var perc float64
 			var amt int
 			if _, err := fmt.Sscan(os.Args[2], &perc); err != nil {
-				fmt.Println("Error:", err)
-				os.Exit(1)
+				fail(err)
 			}
 			if _, err := fmt.Sscan(os.Args[3], &amt); err != nil {
-				fmt.Println("Error:", err)
-				os.Exit(1)
+				fail(err)
 			}
 			if err := utils.RunGenerateNoiseVOPL(perc, amt, os.Args[4]); err != nil {
-				fmt.Println("Error:", err)
-				os.Exit(1)
+				fail(err)
 			}
 		} else if len(os.Args) == 6 {
 			var minP, maxP float64
 			var amt int
 			if _, err := fmt.Sscan(os.Args[2], &minP); err != nil {
-				fmt.Println("Error:", err)
-				os.Exit(1)
+				fail(err)
 			}
 			if _, err := fmt.Sscan(os.Args[3], &maxP); err != nil {
-				fmt.Println("Error:", err)
-				os.Exit(1)
+				fail(err)
 			}
 			if _, err := fmt.Sscan(os.Args[4], &amt); err != nil {
-				fmt.Println("Error:", err)
-				os.Exit(1)
+				fail(err)
 			}
 			if err := utils.RunGenerateNoiseVOPLRange(minP, maxP, amt, os.Args[5]); err != nil {
-				fmt.Println("Error:", err)
-				os.Exit(1)
+				fail(err)
 			}
 		} else {
 			usage()
 			os.Exit(1)
 		}
+	case "transform":
+		if len(os.Args) < 5 {
+			usage()
+			os.Exit(1)
+		}
+		if err := utils.RunTransformVOPL(os.Args[2], os.Args[3], os.Args[4:]); err != nil {
+			fail(err)
+		}
+	case "csg":
+		if len(os.Args) < 6 {
+			usage()
+			os.Exit(1)
+		}
+		op, err := vopl.ParseCSGOp(os.Args[2])
+		if err != nil {
+			fail(err)
+		}
+		opts, err := utils.ParseCSGOptions(os.Args[6:])
+		if err != nil {
+			fail(err)
+		}
+		if err := utils.RunCSGVOPL(op, os.Args[3], os.Args[4], os.Args[5], opts); err != nil {
+			fail(err)
+		}
+	case "stats":
+		if len(os.Args) != 3 && len(os.Args) != 4 {
+			usage()
+			os.Exit(1)
+		}
+		format := "json"
+		if len(os.Args) == 4 {
+			format = os.Args[3]
+		}
+		if err := utils.RunStats(os.Args[2], format, os.Stdout); err != nil {
+			fail(err)
+		}
+		// stdout carries the report; skip the completion banner
+		return
+	case "diff":
+		if len(os.Args) != 4 && len(os.Args) != 5 {
+			usage()
+			os.Exit(1)
+		}
+		out := ""
+		if len(os.Args) == 5 {
+			out = os.Args[4]
+		}
+		if err := utils.RunDiffVOPL(os.Args[2], os.Args[3], out); err != nil {
+			fail(err)
+		}
+		if out == "" {
+			return
+		}
+	case "hollow":
+		if len(os.Args) != 4 && len(os.Args) != 5 {
+			usage()
+			os.Exit(1)
+		}
+		thickness := 1
+		if len(os.Args) == 5 {
+			if _, err := fmt.Sscan(os.Args[4], &thickness); err != nil {
+				fail(err)
+			}
+		}
+		if err := utils.RunHollowVOPL(os.Args[2], os.Args[3], thickness); err != nil {
+			fail(err)
+		}
+	case "lod":
+		if len(os.Args) != 4 && len(os.Args) != 5 {
+			usage()
+			os.Exit(1)
+		}
+		mode := vopl.LODMajority
+		if len(os.Args) == 5 {
+			m, err := vopl.ParseLODMode(os.Args[4])
+			if err != nil {
+				fail(err)
+			}
+			mode = m
+		}
+		if err := utils.RunLOD(os.Args[2], os.Args[3], mode); err != nil {
+			fail(err)
+		}
+	case "upscale":
+		if len(os.Args) != 5 && len(os.Args) != 6 {
+			usage()
+			os.Exit(1)
+		}
+		var factor int
+		if _, err := fmt.Sscan(os.Args[3], &factor); err != nil {
+			fail(err)
+		}
+		var origin vopl.ChunkCoord
+		if len(os.Args) == 6 {
+			c, err := utils.ParseChunkCoord(os.Args[5])
+			if err != nil {
+				fail(err)
+			}
+			origin = c
+		}
+		if err := utils.RunUpscale(os.Args[2], factor, os.Args[4], origin); err != nil {
+			fail(err)
+		}
+	case "sdf":
+		if len(os.Args) != 4 && len(os.Args) != 5 {
+			usage()
+			os.Exit(1)
+		}
+		supersample := 1
+		if len(os.Args) == 5 {
+			if _, err := fmt.Sscan(os.Args[4], &supersample); err != nil {
+				fail(err)
+			}
+		}
+		if err := utils.RunSDF(os.Args[2], os.Args[3], supersample); err != nil {
+			fail(err)
+		}
+	case "eval":
+		if len(os.Args) < 4 || len(os.Args) > 6 {
+			usage()
+			os.Exit(1)
+		}
+		var err error
+		switch len(os.Args) {
+		case 6:
+			var box vopl.Box
+			if box, err = utils.ParseRegion(os.Args[4], os.Args[5]); err == nil {
+				err = utils.RunEvalRegion(os.Args[2], os.Args[3], box)
+			}
+		case 5:
+			var chunk vopl.ChunkCoord
+			if chunk, err = utils.ParseChunkCoord(os.Args[4]); err == nil {
+				err = utils.RunEval(os.Args[2], os.Args[3], chunk)
+			}
+		default:
+			err = utils.RunEval(os.Args[2], os.Args[3], vopl.ChunkCoord{})
+		}
+		if err != nil {
+			fail(err)
+		}
+	case "text":
+		if len(os.Args) < 4 {
+			usage()
+			os.Exit(1)
+		}
+		origin, opts, err := utils.ParseTextOptions(os.Args[4:])
+		if err != nil {
+			fail(err)
+		}
+		if err := utils.RunText(os.Args[2], os.Args[3], origin, opts); err != nil {
+			fail(err)
+		}
+	case "copy":
+		if len(os.Args) != 6 && len(os.Args) != 7 {
+			usage()
+			os.Exit(1)
+		}
+		box, err := utils.ParseRegion(os.Args[3], os.Args[4])
+		if err != nil {
+			fail(err)
+		}
+		var anchor [3]int
+		if len(os.Args) == 7 {
+			if anchor, err = utils.ParseAnchor(os.Args[6]); err != nil {
+				fail(err)
+			}
+		}
+		if err := utils.RunCopy(os.Args[2], box, anchor, os.Args[5]); err != nil {
+			fail(err)
+		}
+	case "paste":
+		if len(os.Args) < 5 {
+			usage()
+			os.Exit(1)
+		}
+		at, err := utils.ParsePosition(os.Args[4])
+		if err != nil {
+			fail(err)
+		}
+		transparent := false
+		var ops []string
+		for _, a := range os.Args[5:] {
+			if a == "transparent" {
+				transparent = true
+			} else {
+				ops = append(ops, a)
+			}
+		}
+		if err := utils.RunPaste(os.Args[2], os.Args[3], at, ops, transparent); err != nil {
+			fail(err)
+		}
+	case "find":
+		if len(os.Args) < 4 {
+			usage()
+			os.Exit(1)
+		}
+		opts, err := utils.ParseTemplateOptions(os.Args[4:])
+		if err != nil {
+			fail(err)
+		}
+		if err := utils.RunFind(os.Args[2], os.Args[3], opts, os.Stdout); err != nil {
+			fail(err)
+		}
+		return
+	case "similar":
+		if len(os.Args) != 3 && len(os.Args) != 4 {
+			usage()
+			os.Exit(1)
+		}
+		threshold := 0.9
+		if len(os.Args) == 4 {
+			if _, err := fmt.Sscan(os.Args[3], &threshold); err != nil {
+				fail(err)
+			}
+		}
+		if err := utils.RunSimilar(os.Args[2], threshold, os.Stdout); err != nil {
+			fail(err)
+		}
+		return
+	case "path":
+		if len(os.Args) < 5 {
+			usage()
+			os.Exit(1)
+		}
+		start, err := utils.ParsePosition(os.Args[3])
+		if err != nil {
+			fail(err)
+		}
+		goal, err := utils.ParsePosition(os.Args[4])
+		if err != nil {
+			fail(err)
+		}
+		opts, err := utils.ParsePathOptions(os.Args[5:])
+		if err != nil {
+			fail(err)
+		}
+		if err := utils.RunPath(os.Args[2], start, goal, opts, os.Stdout); err != nil {
+			fail(err)
+		}
+		return
+	case "navmesh":
+		if len(os.Args) < 5 {
+			usage()
+			os.Exit(1)
+		}
+		box, err := utils.ParseRegion(os.Args[3], os.Args[4])
+		if err != nil {
+			fail(err)
+		}
+		opts, err := utils.ParseWalkOptions(os.Args[5:])
+		if err != nil {
+			fail(err)
+		}
+		if err := utils.RunNavmesh(os.Args[2], box, opts, os.Stdout); err != nil {
+			fail(err)
+		}
+		return
 	default:
 		usage()
 		os.Exit(1)
@@ -0,0 +1,78 @@
+package test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+const (
+	sand  = 38
+	water = 19
+)
+
+func TestAutomaton_SandFallsAndReportsChanges(t *testing.T) {
+	var g vopl.VoxelGrid
+	g.Set(4, 3, 4, sand)
+	a := vopl.NewGridAutomaton(&g, map[uint8]vopl.Rule{sand: vopl.FallingRule()}, 1)
+
+	changes := a.Step()
+	want := []vopl.WorldChange{
+		{Pos: [3]int{4, 2, 4}, From: 0, To: sand},
+		{Pos: [3]int{4, 3, 4}, From: sand, To: 0},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("changes = %+v", changes)
+	}
+	up := vopl.ChangesToUpdates(changes)
+	if len(up["0"]) != 2 || up["0"]["1076"] != 0 || up["0"]["1060"] != sand {
+		t.Fatalf("updates = %v", up)
+	}
+	for range 5 {
+		a.Step()
+	}
+	if g.Get(4, 0, 4) != sand || g.CountNonZero() != 1 {
+		t.Fatalf("sand should rest on the floor")
+	}
+	if len(a.Step()) != 0 {
+		t.Fatalf("resting sand should not change")
+	}
+}
+
+func TestAutomaton_DeterministicWithSeed(t *testing.T) {
+	run := func(seed int64) vopl.VoxelGrid {
+		var g vopl.VoxelGrid
+		g.FillBox(6, 8, 6, 10, 12, 10, water)
+		a := vopl.NewGridAutomaton(&g, map[uint8]vopl.Rule{water: vopl.LiquidRule()}, seed)
+		for range 20 {
+			a.Step()
+		}
+		return g
+	}
+	first, second := run(42), run(42)
+	if first != second {
+		t.Fatalf("same seed produced different results")
+	}
+	if first.CountNonZero() != 64 {
+		t.Fatalf("water volume not conserved: %d", first.CountNonZero())
+	}
+}
+
+func TestAutomaton_AcrossChunks(t *testing.T) {
+	top := new(vopl.VoxelGrid)
+	top.Set(0, 0, 0, sand)
+	world := vopl.ChunkMap{{0, 1, 0}: top}
+	a := &vopl.Automaton{World: world, Rules: map[uint8]vopl.Rule{sand: vopl.FallingRule()}}
+	a.Step()
+	if world.Get(0, 15, 0) != sand {
+		t.Fatalf("sand should fall into the chunk below")
+	}
+	if _, ok := world[vopl.ChunkCoord{0, 1, 0}]; ok {
+		t.Fatalf("emptied chunk should be removed")
+	}
+	up := vopl.ChangesToUpdates([]vopl.WorldChange{{Pos: [3]int{0, 15, 0}, To: sand}})
+	if _, ok := up[vopl.ChunkCoord{0, 0, 0}.ID()]; !ok {
+		t.Fatalf("updates not keyed by chunk id: %v", up)
+	}
+}
@@ -0,0 +1,211 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/voxelsplace/vopl/go/utils"
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+func TestClipboard_CopyPasteAcrossChunks(t *testing.T) {
+	world := vopl.ChunkMap{}
+	world.Set(14, 1, 2, 7)
+	world.Set(17, 1, 2, 8) // next chunk along x
+	clip, err := vopl.CopyRegion(world, vopl.Box{Min: [3]int{14, 1, 2}, Max: [3]int{18, 2, 3}})
+	if err != nil {
+		t.Fatalf("CopyRegion: %v", err)
+	}
+	if clip.Size != [3]int{4, 1, 1} || clip.Get(0, 0, 0) != 7 || clip.Get(3, 0, 0) != 8 {
+		t.Fatalf("unexpected clipboard %+v", clip)
+	}
+	clip.Anchor = [3]int{1, 0, 0}
+	if n := clip.Paste(world, [3]int{32, 40, 0}, true); n != 2 {
+		t.Fatalf("transparent paste wrote %d, want 2", n)
+	}
+	if world.Get(31, 40, 0) != 7 || world.Get(34, 40, 0) != 8 {
+		t.Fatalf("pasted voxels not at anchor-relative positions")
+	}
+	world.Set(32, 40, 0, 9)
+	clip.Paste(world, [3]int{32, 40, 0}, true)
+	if world.Get(32, 40, 0) != 9 {
+		t.Fatalf("transparent paste overwrote an existing voxel with air")
+	}
+	if n := clip.Paste(world, [3]int{32, 40, 0}, false); n != 4 || world.Get(32, 40, 0) != 0 {
+		t.Fatalf("opaque paste wrote %d and left %d", n, world.Get(32, 40, 0))
+	}
+}
+
+func TestClipboard_RotateMatchesGrid(t *testing.T) {
+	g := makeSmallGrid()
+	g.Set(3, 9, 14, 12)
+	clip, err := vopl.CopyRegion(g, vopl.Box{Max: [3]int{vopl.Width, vopl.Height, vopl.Depth}})
+	if err != nil {
+		t.Fatalf("CopyRegion: %v", err)
+	}
+	for _, axis := range []vopl.Axis{vopl.AxisX, vopl.AxisY, vopl.AxisZ} {
+		for turns := -1; turns <= 2; turns++ {
+			want := g.Rotate90(axis, turns)
+			got := clip.Rotate90(axis, turns)
+			var out vopl.VoxelGrid
+			got.Paste(&out, got.Anchor, false)
+			if out != *want {
+				t.Fatalf("axis %v turns %d: clipboard rotation differs from grid rotation", axis, turns)
+			}
+		}
+		m := clip.Mirror(axis)
+		var out vopl.VoxelGrid
+		m.Paste(&out, m.Anchor, false)
+		if out != *g.Mirror(axis) {
+			t.Fatalf("axis %v: clipboard mirror differs from grid mirror", axis)
+		}
+	}
+}
+
+func TestClipboard_RotateNonCubeAnchor(t *testing.T) {
+	clip, err := vopl.NewClipboard([3]int{3, 1, 2})
+	if err != nil {
+		t.Fatalf("NewClipboard: %v", err)
+	}
+	clip.Set(2, 0, 0, 5)
+	clip.Anchor = [3]int{2, 0, 0}
+	r := clip.Rotate90(vopl.AxisY, 1)
+	// about Y, +X moves to -Z: size becomes 2x1x3 and (2,0,0) goes to (0,0,0)
+	if r.Size != [3]int{2, 1, 3} || r.Anchor != [3]int{0, 0, 0} || r.Get(0, 0, 0) != 5 {
+		t.Fatalf("rotated %+v", r)
+	}
+	if m := clip.Mirror(vopl.AxisX); m.Anchor != [3]int{0, 0, 0} || m.Get(0, 0, 0) != 5 {
+		t.Fatalf("mirrored %+v", m)
+	}
+	if clip.Rotate90(vopl.AxisZ, 4).Size != clip.Size {
+		t.Fatalf("four turns should restore the size")
+	}
+}
+
+func TestClipboard_PrefabRoundTrip(t *testing.T) {
+	clip, _ := vopl.NewClipboard([3]int{20, 3, 2})
+	clip.Set(19, 2, 1, 33)
+	clip.Anchor = [3]int{10, 0, 1}
+	path := filepath.Join(t.TempDir(), "p.voplfab")
+	if err := vopl.SavePrefab(clip, path); err != nil {
+		t.Fatalf("SavePrefab: %v", err)
+	}
+	got, err := vopl.LoadPrefab(path)
+	if err != nil {
+		t.Fatalf("LoadPrefab: %v", err)
+	}
+	if got.Size != clip.Size || got.Anchor != clip.Anchor || got.Get(19, 2, 1) != 33 {
+		t.Fatalf("round trip mismatch: %+v", got)
+	}
+	clip.Anchor = [3]int{20, 0, 0}
+	if _, err := clip.MarshalBinary(); err == nil {
+		t.Fatalf("expected error for anchor outside the prefab")
+	}
+	if _, err := vopl.UnmarshalClipboard([]byte("VOPL")); err == nil {
+		t.Fatalf("expected error for bad magic")
+	}
+}
+
+func TestClipboard_RunCopyPaste(t *testing.T) {
+	dir := t.TempDir()
+	src := vopl.NewWorld()
+	src.Set(15, 0, 0, 4)
+	src.Set(16, 0, 0, 5)
+	worldDir := filepath.Join(dir, "world")
+	if err := src.Save(worldDir); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	fab := filepath.Join(dir, "two.voplfab")
+	if err := utils.RunCopy(worldDir, vopl.Box{Min: [3]int{15, 0, 0}, Max: [3]int{17, 1, 1}}, [3]int{0, 0, 0}, fab); err != nil {
+		t.Fatalf("RunCopy: %v", err)
+	}
+	if err := utils.RunPaste(fab, worldDir, [3]int{31, 16, 0}, []string{"mirrorx"}, true); err != nil {
+		t.Fatalf("RunPaste: %v", err)
+	}
+	pack := filepath.Join(dir, "world.voplpack")
+	if err := utils.RunPaste(fab, pack, [3]int{0, 0, 0}, nil, false); err != nil {
+		t.Fatalf("RunPaste pack: %v", err)
+	}
+	world, err := vopl.LoadWorld(worldDir)
+	if err != nil {
+		t.Fatalf("LoadWorld: %v", err)
+	}
+	// mirrorx moves the anchor to the other end: (16,0,0)'s color lands at 30, (15,0,0)'s at 31
+	if world.Get(30, 16, 0) != 5 || world.Get(31, 16, 0) != 4 || world.Get(15, 0, 0) != 4 || world.Len() != 3 {
+		t.Fatalf("unexpected world after paste: %d chunks", world.Len())
+	}
+	packed, err := vopl.LoadWorld(pack)
+	if err != nil {
+		t.Fatalf("LoadWorld pack: %v", err)
+	}
+	if packed.Get(0, 0, 0) != 4 || packed.Get(1, 0, 0) != 5 {
+		t.Fatalf("unexpected pack contents")
+	}
+}
+
+func TestClipboard_RunPasteRemovesEmptiedChunks(t *testing.T) {
+	dir := t.TempDir()
+	air, err := vopl.NewClipboard([3]int{1, 1, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fab := filepath.Join(dir, "air.voplfab")
+	if err := vopl.SavePrefab(air, fab); err != nil {
+		t.Fatal(err)
+	}
+	src := vopl.NewWorld()
+	src.Set(3, 0, 0, 4)
+	src.Set(20, 0, 0, 5)
+
+	worldDir := filepath.Join(dir, "world")
+	if err := src.Save(worldDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := utils.RunPaste(fab, worldDir, [3]int{20, 0, 0}, []string{"roty:2"}, false); err != nil {
+		t.Fatalf("RunPaste: %v", err)
+	}
+	if files, _ := os.ReadDir(worldDir); len(files) != 1 || files[0].Name() != "0.vopl" {
+		t.Fatalf("emptied chunk file not removed: %v", files)
+	}
+
+	// a pack keeps its layout and compression and may end up empty
+	p := &vopl.Pack{}
+	for _, name := range []string{"0.vopl", "01.vopl"} {
+		var g vopl.VoxelGrid
+		g.Set(3, 0, 0, 4)
+		data := vopl.SaveVoplGridToBytes(&g)
+		hdr, payload, err := vopl.ParseVOPLHeaderFromBytes(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hdr.PLen = 0
+		p.Header = hdr
+		p.Entries = append(p.Entries, vopl.PackEntry{Name: name, Enc: data[5], Payload: payload})
+	}
+	data, err := p.MarshalEx(vopl.LayoutCDC, vopl.PackCompZstd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pack := filepath.Join(dir, "world.voplpack")
+	if err := os.WriteFile(pack, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := utils.RunPaste(fab, pack, [3]int{3, 0, 0}, nil, false); err != nil {
+		t.Fatalf("RunPaste pack: %v", err)
+	}
+	data, _ = os.ReadFile(pack)
+	got, layout, comp, err := vopl.UnmarshalPackEx(data)
+	if err != nil || layout != vopl.LayoutCDC || comp != vopl.PackCompZstd {
+		t.Fatalf("pack re-encoded as layout %d, compression %d: %v", layout, comp, err)
+	}
+	if len(got.Entries) != 1 || got.Entries[0].Name != "01.vopl" {
+		t.Fatalf("unexpected entries %+v", got.Entries)
+	}
+	if err := utils.RunPaste(fab, pack, [3]int{19, 0, 0}, nil, false); err != nil {
+		t.Fatalf("RunPaste emptying the pack: %v", err)
+	}
+	if w, err := vopl.LoadWorld(pack); err != nil || w.Len() != 0 {
+		t.Fatalf("pack not emptied: %v", err)
+	}
+}
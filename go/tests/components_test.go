@@ -0,0 +1,40 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+func TestComponents_ConnectivityAndFloating(t *testing.T) {
+	var g vopl.VoxelGrid
+	g.FillBox(0, 0, 0, 2, 3, 1, 4) // grounded pillar
+	g.Set(2, 3, 0, 5)              // touches pillar only by an edge
+	g.Set(8, 8, 8, 6)              // isolated floating voxel
+	g.Set(9, 9, 9, 6)              // corner-adjacent to the previous one
+
+	if _, comps := vopl.LabelComponents(&g, vopl.Conn6); len(comps) != 4 {
+		t.Fatalf("6-connectivity components = %d, want 4", len(comps))
+	}
+	if _, comps := vopl.LabelComponents(&g, vopl.Conn18); len(comps) != 3 {
+		t.Fatalf("18-connectivity components = %d, want 3", len(comps))
+	}
+	_, comps := vopl.LabelComponents(&g, vopl.Conn26)
+	if len(comps) != 2 {
+		t.Fatalf("26-connectivity components = %d, want 2", len(comps))
+	}
+	if comps[0].Size != 7 || comps[0].Colors[4] != 6 || comps[0].Colors[5] != 1 || !comps[0].Grounded {
+		t.Fatalf("unexpected pillar component: %+v", comps[0])
+	}
+	if comps[0].Bounds != (vopl.Box{Min: [3]int{0, 0, 0}, Max: [3]int{3, 4, 1}}) {
+		t.Fatalf("pillar bounds = %+v", comps[0].Bounds)
+	}
+
+	floating := vopl.FloatingComponents(&g, vopl.Conn6)
+	if len(floating) != 3 {
+		t.Fatalf("floating (6) = %d, want 3", len(floating))
+	}
+	if n := g.RemoveFloating(vopl.Conn26); n != 2 || g.CountNonZero() != 7 {
+		t.Fatalf("RemoveFloating removed %d, remaining %d", n, g.CountNonZero())
+	}
+}
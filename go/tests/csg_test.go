@@ -0,0 +1,37 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+func TestCSG_Operations(t *testing.T) {
+	var a, b vopl.VoxelGrid
+	a.FillBox(0, 0, 0, 4, 1, 1, 1) // x in [0,4)
+	b.FillBox(0, 0, 0, 4, 1, 1, 2) // shifted by 2 -> x in [2,6)
+	opts := vopl.CSGOptions{Offset: [3]int{2, 0, 0}}
+
+	u := vopl.Union(&a, &b, opts)
+	if u.CountNonZero() != 6 || u.Get(2, 0, 0) != 1 || u.Get(5, 0, 0) != 2 {
+		t.Fatalf("union wrong: count=%d", u.CountNonZero())
+	}
+	opts.Overlap = vopl.OverlapKeepB
+	i := vopl.Intersect(&a, &b, opts)
+	if i.CountNonZero() != 2 || i.Get(3, 0, 0) != 2 {
+		t.Fatalf("intersect wrong: count=%d", i.CountNonZero())
+	}
+	opts.Overlap = vopl.OverlapFixed
+	opts.Color = 9
+	if vopl.Intersect(&a, &b, opts).Get(2, 0, 0) != 9 {
+		t.Fatalf("fixed overlap color not applied")
+	}
+	s := vopl.Subtract(&a, &b, opts)
+	if s.CountNonZero() != 2 || s.Get(1, 0, 0) != 1 || s.Get(2, 0, 0) != 0 {
+		t.Fatalf("subtract wrong: count=%d", s.CountNonZero())
+	}
+	x := vopl.Xor(&a, &b, opts)
+	if x.CountNonZero() != 4 || x.Get(3, 0, 0) != 0 || x.Get(4, 0, 0) != 2 {
+		t.Fatalf("xor wrong: count=%d", x.CountNonZero())
+	}
+}
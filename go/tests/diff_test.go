@@ -0,0 +1,60 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/voxelsplace/vopl/go/utils"
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+func TestDiff_RoundTripThroughUpdateVOPL(t *testing.T) {
+	a := makeSmallGrid()
+	b := *a
+	b[0][0][0] = 0   // removed
+	b[1][2][1] = 42  // recolored
+	b[9][4][15] = 12 // added
+	changes := vopl.Diff(a, &b)
+	if len(changes) != 3 {
+		t.Fatalf("Diff returned %d changes, want 3", len(changes))
+	}
+	for i := 1; i < len(changes); i++ {
+		if changes[i-1].Index() >= changes[i].Index() {
+			t.Fatalf("changes not in ascending index order")
+		}
+	}
+
+	aPath := "output/diff_a.vopl"
+	bPath := "output/7.vopl"
+	jsonPath := filepath.Join(t.TempDir(), "diff.json")
+	outPath := "output/diff_applied.vopl"
+	_ = os.MkdirAll(filepath.Dir(aPath), 0o755)
+	if err := vopl.SaveVoplGrid(a, aPath); err != nil {
+		t.Fatalf("save a: %v", err)
+	}
+	if err := vopl.SaveVoplGrid(&b, bPath); err != nil {
+		t.Fatalf("save b: %v", err)
+	}
+	if err := utils.RunDiffVOPL(aPath, bPath, jsonPath); err != nil {
+		t.Fatalf("RunDiffVOPL: %v", err)
+	}
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("read diff: %v", err)
+	}
+	up, err := vopl.ParseUpdates(data)
+	if err != nil || len(up["7"]) != 3 {
+		t.Fatalf("unexpected diff document %s (%v)", data, err)
+	}
+	if err := utils.RunUpdateVOPL(data, aPath, outPath); err != nil {
+		t.Fatalf("RunUpdateVOPL: %v", err)
+	}
+	got, err := vopl.LoadVoplGrid(outPath)
+	if err != nil {
+		t.Fatalf("load result: %v", err)
+	}
+	if *got != b {
+		t.Fatalf("applying diff did not reproduce b")
+	}
+}
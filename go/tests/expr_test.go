@@ -0,0 +1,163 @@
+package test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+func TestExpr_Disc(t *testing.T) {
+	e, err := vopl.ParseExpr("x*x + z*z < 36 && y < 8 ? 12 : 0")
+	if err != nil {
+		t.Fatalf("ParseExpr: %v", err)
+	}
+	var g vopl.VoxelGrid
+	n := e.FillGrid(&g, [3]int{})
+	want := 0
+	for x := range 16 {
+		for z := range 16 {
+			if x*x+z*z < 36 {
+				want += 8
+			}
+		}
+	}
+	if n != want || g.CountNonZero() != want {
+		t.Fatalf("filled %d (count %d), want %d", n, g.CountNonZero(), want)
+	}
+	if g.Get(0, 7, 0) != 12 || g.Get(0, 8, 0) != 0 || g.Get(6, 0, 0) != 0 {
+		t.Fatalf("unexpected cells")
+	}
+}
+
+func TestExpr_Operators(t *testing.T) {
+	cases := map[string]float64{
+		"1 + 2 * 3":                       7,
+		"(1 + 2) * 3":                     9,
+		"-2 * -3":                         6,
+		"7 % 4":                           3,
+		"mod(-1, 16)":                     15,
+		"!0 + !5":                         1,
+		"1 < 2 == 1":                      1,
+		"0 || 0 ? 4 : 1 ? 5 : 6":          5,
+		"max(1, 9, 3) - min(4, 2)":        7,
+		"clamp(20, 0, 10)":                10,
+		"pick(1, 10, 20, 30)":             20,
+		"pick(99, 10, 20, 30)":            30,
+		"pick(-1, 10, 20)":                10,
+		"floor(x / 2) + y * 10 + z * 100": 212,
+		"lx + ly + lz":                    7,
+	}
+	for src, want := range cases {
+		e, err := vopl.ParseExpr(src)
+		if err != nil {
+			t.Fatalf("ParseExpr(%q): %v", src, err)
+		}
+		if got := e.Eval(4, 1, 2); got != want {
+			t.Errorf("%q = %v, want %v", src, got, want)
+		}
+	}
+}
+
+func TestExpr_PaletteLookup(t *testing.T) {
+	for i := uint8(1); i < 64; i++ {
+		c, err := vopl.ParseHexColor(vopl.Palette[i])
+		if err != nil {
+			t.Fatalf("palette %d: %v", i, err)
+		}
+		idx := vopl.NearestPaletteIndex(uint8(c[0]*255+0.5), uint8(c[1]*255+0.5), uint8(c[2]*255+0.5))
+		if idx != i {
+			c2, _ := vopl.ParseHexColor(vopl.Palette[idx])
+			if c2 != c {
+				t.Fatalf("NearestPaletteIndex(palette %d) = %d", i, idx)
+			}
+		}
+	}
+	e, err := vopl.ParseExpr("#" + vopl.Palette[12][1:])
+	if err != nil {
+		t.Fatalf("ParseExpr: %v", err)
+	}
+	if got := e.Color(0, 0, 0); got != 12 {
+		t.Fatalf("color literal = %d, want 12", got)
+	}
+}
+
+func TestExpr_ColorClamp(t *testing.T) {
+	for src, want := range map[string]uint8{"-5": 0, "300": 255, "0/0": 0, "2.6": 3} {
+		e, err := vopl.ParseExpr(src)
+		if err != nil {
+			t.Fatalf("ParseExpr(%q): %v", src, err)
+		}
+		if got := e.Color(0, 0, 0); got != want {
+			t.Errorf("Color(%q) = %d, want %d", src, got, want)
+		}
+	}
+}
+
+func TestExpr_Noise(t *testing.T) {
+	e, err := vopl.ParseExpr("noise(x*0.3, y*0.3, z*0.3) > 0 ? 5 : 0")
+	if err != nil {
+		t.Fatalf("ParseExpr: %v", err)
+	}
+	var a, b vopl.VoxelGrid
+	e.FillGrid(&a, [3]int{})
+	e.FillGrid(&b, [3]int{})
+	if a != b {
+		t.Fatalf("noise is not deterministic")
+	}
+	if n := a.CountNonZero(); n == 0 || n == 4096 {
+		t.Fatalf("noise fill count = %d", n)
+	}
+	for _, p := range [][3]float64{{0.3, 1.7, 2.2}, {10.5, -3.25, 7.75}} {
+		if v := vopl.Noise3(p[0], p[1], p[2]); math.Abs(v) > 1.1 {
+			t.Fatalf("Noise3%v = %v out of range", p, v)
+		}
+	}
+}
+
+func TestExpr_FBMOctaveClamp(t *testing.T) {
+	// a huge octave count must neither hang nor overflow to Inf/NaN
+	e, err := vopl.ParseExpr("fbm(x*0.1, y*0.1, z*0.1, 1000000000) > 0 ? 5 : 0")
+	if err != nil {
+		t.Fatalf("ParseExpr: %v", err)
+	}
+	var a, b vopl.VoxelGrid
+	e.FillGrid(&a, [3]int{})
+	want, _ := vopl.ParseExpr("fbm(x*0.1, y*0.1, z*0.1, 16) > 0 ? 5 : 0")
+	want.FillGrid(&b, [3]int{})
+	if a != b {
+		t.Fatalf("fbm with 1000000000 octaves differs from %d octaves", vopl.MaxFBMOctaves)
+	}
+	if v := vopl.FBM3(0.3, 1.7, 2.2, 5000); math.IsNaN(v) || math.Abs(v) > 1.1 {
+		t.Fatalf("FBM3 with 5000 octaves = %v", v)
+	}
+}
+
+func TestExpr_FillRegion(t *testing.T) {
+	e, err := vopl.ParseExpr("y == 0 ? 3 : 0")
+	if err != nil {
+		t.Fatalf("ParseExpr: %v", err)
+	}
+	m := vopl.ChunkMap{}
+	n, err := e.FillRegion(m, vopl.Box{Min: [3]int{10, 0, 0}, Max: [3]int{20, 4, 2}})
+	if err != nil {
+		t.Fatalf("FillRegion: %v", err)
+	}
+	if n != 20 || len(m) != 2 {
+		t.Fatalf("n=%d chunks=%d, want 20 and 2", n, len(m))
+	}
+	if m.Get(19, 0, 1) != 3 || m.Get(19, 1, 1) != 0 {
+		t.Fatalf("unexpected region cells")
+	}
+	if _, err := e.FillRegion(m, vopl.Box{Min: [3]int{-1, 0, 0}, Max: [3]int{1, 1, 1}}); err == nil {
+		t.Fatalf("expected error for negative region")
+	}
+}
+
+func TestExpr_Errors(t *testing.T) {
+	for _, src := range []string{"", "1 +", "(1", "foo", "bar(1)", "abs(1, 2)", "1 ? 2", "#12345", "1 $ 2", "x y"} {
+		if _, err := vopl.ParseExpr(src); err == nil {
+			t.Errorf("ParseExpr(%q) succeeded, want error", src)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+func TestGrid_GetSetBounds(t *testing.T) {
+	var g vopl.VoxelGrid
+	if !g.Set(1, 2, 3, 9) {
+		t.Fatalf("Set in bounds returned false")
+	}
+	if g[2][1][3] != 9 || g.Get(1, 2, 3) != 9 {
+		t.Fatalf("Set did not write grid[y][x][z]")
+	}
+	if g.Set(-1, 0, 0, 1) || g.Set(0, vopl.Height, 0, 1) {
+		t.Fatalf("Set out of bounds returned true")
+	}
+	if g.Get(vopl.Width, 0, 0) != 0 {
+		t.Fatalf("Get out of bounds should be 0")
+	}
+}
+
+func TestGrid_FillReplaceCount(t *testing.T) {
+	var g vopl.VoxelGrid
+	if n := g.FillBox(-2, 0, 14, 2, 3, 20, 5); n != 2*3*2 {
+		t.Fatalf("FillBox clipped count = %d, want 12", n)
+	}
+	if g.CountNonZero() != 12 {
+		t.Fatalf("CountNonZero = %d, want 12", g.CountNonZero())
+	}
+	if n := g.ReplaceColor(5, 7); n != 12 {
+		t.Fatalf("ReplaceColor = %d, want 12", n)
+	}
+	n := 0
+	for v := range g.Occupied() {
+		if v.Color != 7 || g.Get(v.X, v.Y, v.Z) != 7 {
+			t.Fatalf("unexpected voxel %+v", v)
+		}
+		n++
+	}
+	if n != 12 {
+		t.Fatalf("Occupied yielded %d, want 12", n)
+	}
+}
+
+func TestGrid_LinearIndexRoundTrip(t *testing.T) {
+	for _, c := range [][3]int{{0, 0, 0}, {15, 0, 0}, {0, 0, 15}, {3, 7, 11}} {
+		idx := vopl.LinearIndex(c[0], c[1], c[2])
+		x, y, z := vopl.XYZFromLinearIndex(idx)
+		if x != c[0] || y != c[1] || z != c[2] {
+			t.Fatalf("round trip %v -> %d -> (%d,%d,%d)", c, idx, x, y, z)
+		}
+	}
+	if vopl.LinearIndex(0, 0, 15) != 3840 {
+		t.Fatalf("LinearIndex(0,0,15) = %d, want 3840", vopl.LinearIndex(0, 0, 15))
+	}
+}
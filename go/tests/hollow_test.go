@@ -0,0 +1,52 @@
+package test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+func TestHollow_KeepsMeshIdentical(t *testing.T) {
+	var g vopl.VoxelGrid
+	g.FillBox(2, 0, 2, 12, 10, 12, 9) // 10x10x10 solid cube
+	want := vopl.GenerateMesh(&g)
+
+	h := g
+	removed := h.Hollow(1)
+	if removed != 8*8*8 {
+		t.Fatalf("Hollow(1) removed %d, want 512", removed)
+	}
+	if got := vopl.GenerateMesh(&h); !reflect.DeepEqual(got, want) {
+		t.Fatalf("mesh changed after hollowing")
+	}
+	if h.CountNonZero() != g.CountNonZero()-removed {
+		t.Fatalf("voxel count mismatch after hollowing")
+	}
+
+	thick := g
+	if removed := thick.Hollow(2); removed != 6*6*6 {
+		t.Fatalf("Hollow(2) removed %d, want 216", removed)
+	}
+}
+
+func TestMesh_SkipsSealedCavityFaces(t *testing.T) {
+	// A 6x6x6 box with a 2x2x2 sealed cavity: before exterior-air culling the mesh
+	// also carried the cavity's 6 inward quads and its 24 exposed faces.
+	var g vopl.VoxelGrid
+	g.FillBox(0, 0, 0, 6, 6, 6, 5)
+	g.FillBox(2, 2, 2, 4, 4, 4, 0)
+
+	if quads := len(vopl.GenerateMesh(&g).Indices) / 6; quads != 6 {
+		t.Fatalf("quads = %d, want 6 (outer faces only)", quads)
+	}
+	if s := vopl.Stats(&g); s.ExposedFaces != 6*36 || s.QuadCount != 6 {
+		t.Fatalf("exposed faces = %d, quads = %d, want 216 and 6", s.ExposedFaces, s.QuadCount)
+	}
+
+	open := g
+	open.FillBox(2, 4, 2, 3, 6, 3, 0) // shaft from the cavity to the top face
+	if s := vopl.Stats(&open); s.ExposedFaces <= 6*36 {
+		t.Fatalf("opened cavity still culled: %d exposed faces", s.ExposedFaces)
+	}
+}
@@ -0,0 +1,112 @@
+package test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/voxelsplace/vopl/go/api"
+	"github.com/voxelsplace/vopl/go/utils"
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+func TestLight_OpenSkyAndRoof(t *testing.T) {
+	var g vopl.VoxelGrid
+	m := vopl.ComputeLight(&g, vopl.DefaultLightOptions())
+	if m.At(8, 0, 8) != vopl.MaxLight || m.At(-1, 3, 3) != vopl.MaxLight {
+		t.Fatalf("empty grid should be fully sky lit")
+	}
+	g.FillBox(0, 10, 0, 16, 11, 16, 3)
+	m = vopl.ComputeLight(&g, vopl.DefaultLightOptions())
+	if m.At(8, 12, 8) != 15 {
+		t.Fatalf("above roof = %d, want 15", m.At(8, 12, 8))
+	}
+	if m.At(8, 10, 8) != 0 {
+		t.Fatalf("solid cell = %d, want 0", m.At(8, 10, 8))
+	}
+	// under the roof light leaks from the bottom edge (14) and dims per step: 5 steps up.
+	if got := m.At(8, 5, 8); got != 9 {
+		t.Fatalf("under roof = %d, want 9", got)
+	}
+}
+
+func TestLight_EmissiveInSealedRoom(t *testing.T) {
+	var g vopl.VoxelGrid
+	g.FillBox(4, 4, 4, 12, 12, 12, 3)
+	g.FillBox(5, 5, 5, 11, 11, 11, 0)
+	g.Set(7, 7, 7, 9)
+	m := vopl.ComputeLight(&g, vopl.LightOptions{Emissive: map[uint8]uint8{9: 10}})
+	if m.At(7, 7, 7) != 10 || m.At(8, 7, 7) != 9 || m.At(10, 10, 10) != 1 {
+		t.Fatalf("unexpected room light: %d %d %d", m.At(7, 7, 7), m.At(8, 7, 7), m.At(10, 10, 10))
+	}
+	if m.At(2, 2, 2) != 0 || m.At(-1, 0, 0) != 0 {
+		t.Fatalf("light leaked out of the sealed room")
+	}
+}
+
+func TestLight_LitMeshColors(t *testing.T) {
+	var g vopl.VoxelGrid
+	g.Set(8, 8, 8, 5) // white
+	mesh := vopl.GenerateLitMesh(&g, vopl.ComputeLight(&g, vopl.DefaultLightOptions()))
+	if !mesh.Lit || len(mesh.Vertices) != 24 {
+		t.Fatalf("lit=%v vertices=%d", mesh.Lit, len(mesh.Vertices))
+	}
+	colors, _, err := mesh.VertexColors()
+	if err != nil {
+		t.Fatalf("VertexColors: %v", err)
+	}
+	for q := 0; q < len(mesh.Vertices); q += 4 {
+		bottom := true
+		for _, v := range mesh.Vertices[q : q+4] {
+			bottom = bottom && v.Position[1] == 8
+		}
+		want := float32(1)
+		if bottom {
+			want = 0.8 // the cell below is shaded from the sky column: level 14
+		}
+		for i := q; i < q+4; i++ {
+			if math.Abs(float64(colors[i][0]-want)) > 1e-6 {
+				t.Fatalf("vertex %d at %v: red %v, want %v", i, mesh.Vertices[i].Position, colors[i][0], want)
+			}
+		}
+	}
+	plain := vopl.GenerateMesh(&g)
+	if plain.Lit || len(plain.Vertices) != 24 {
+		t.Fatalf("unlit mesh changed")
+	}
+}
+
+func TestLight_ParseOptions(t *testing.T) {
+	if o, err := utils.ParseLightOptions(nil); o != nil || err != nil {
+		t.Fatalf("no args should disable lighting")
+	}
+	o, err := utils.ParseLightOptions([]string{"sky=12", "emissive=9,10:7"})
+	if err != nil {
+		t.Fatalf("ParseLightOptions: %v", err)
+	}
+	if o.Sky != 12 || o.Emissive[9] != 15 || o.Emissive[10] != 7 {
+		t.Fatalf("parsed %+v", o)
+	}
+	for _, bad := range []string{"sky=16", "emissive=0", "emissive=3:20", "glow"} {
+		if _, err := utils.ParseLightOptions([]string{bad}); err == nil {
+			t.Errorf("ParseLightOptions(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestParseLightOptionsJSON(t *testing.T) {
+	opts, err := api.ParseLightOptionsJSON([]byte(`{"sky": 20, "emissive": {"5": 7.5, "9": -3, "0": 4, "x": 1}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Sky != vopl.MaxLight || len(opts.Emissive) != 2 || opts.Emissive[5] != 7 || opts.Emissive[9] != 0 {
+		t.Fatalf("options = %+v", opts)
+	}
+	if opts, err := api.ParseLightOptionsJSON([]byte(`{"sky": "bright"}`)); err != nil || opts.Sky != vopl.DefaultLightOptions().Sky {
+		t.Fatalf("non-numeric sky: %+v, %v", opts, err)
+	}
+	for _, bad := range []string{`{"emissive": {"5": "15"}}`, `{"emissive": {"5": null}}`, `[1`} {
+		if _, err := api.ParseLightOptionsJSON([]byte(bad)); err == nil {
+			t.Fatalf("expected an error for %s", bad)
+		}
+	}
+}
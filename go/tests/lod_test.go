@@ -0,0 +1,45 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+func TestLOD_Levels(t *testing.T) {
+	var g vopl.VoxelGrid
+	g.FillBox(0, 0, 0, 16, 16, 16, 4)
+	g.FillBox(0, 0, 0, 16, 16, 1, 9) // thin z=0 skin
+
+	levels := vopl.BuildLODs(&g, vopl.LODMajority)
+	if len(levels) != vopl.MaxLODLevel+1 {
+		t.Fatalf("got %d levels", len(levels))
+	}
+	for _, l := range levels {
+		if l.Size != 16>>l.Level || l.Scale != 1<<l.Level {
+			t.Fatalf("level %d has size %d scale %d", l.Level, l.Size, l.Scale)
+		}
+		if n := l.Grid.CountNonZero(); n != l.Size*l.Size*l.Size {
+			t.Fatalf("level %d: %d voxels, want %d", l.Level, n, l.Size*l.Size*l.Size)
+		}
+	}
+	if levels[1].Grid.Get(3, 3, 0) != 4 {
+		t.Fatalf("majority should drop the one-cell skin color")
+	}
+	if vopl.BuildLODs(&g, vopl.LODVisible)[1].Grid.Get(3, 3, 0) != 9 {
+		t.Fatalf("visible mode should keep the exposed skin color")
+	}
+
+	// The coarsest level meshes to a single 16-unit cube.
+	mesh := vopl.GenerateMeshLOD(&levels[vopl.MaxLODLevel].Grid, vopl.MaxLODLevel)
+	if len(mesh.Indices)/6 != 6 {
+		t.Fatalf("level 4 quads = %d, want 6", len(mesh.Indices)/6)
+	}
+	for _, v := range mesh.Vertices {
+		for _, c := range v.Position {
+			if c != 0 && c != 16 {
+				t.Fatalf("vertex %v not on the 16-unit cube", v.Position)
+			}
+		}
+	}
+}
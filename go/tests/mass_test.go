@@ -0,0 +1,38 @@
+package test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+func TestMassProperties_Box(t *testing.T) {
+	var g vopl.VoxelGrid
+	g.FillBox(0, 0, 0, 4, 2, 2, 1) // 4x2x2 box of unit density
+	p := vopl.MassProperties(&g, nil)
+	if p.Volume != 16 || p.Mass != 16 {
+		t.Fatalf("volume/mass = %v/%v", p.Volume, p.Mass)
+	}
+	if p.CenterOfMass != [3]float64{2, 1, 1} {
+		t.Fatalf("center of mass = %v", p.CenterOfMass)
+	}
+	// Solid box a×b×c: Ixx = M(b²+c²)/12
+	want := [3]float64{16 * (4 + 4) / 12.0, 16 * (16 + 4) / 12.0, 16 * (16 + 4) / 12.0}
+	for i := range 3 {
+		if math.Abs(p.Inertia[i][i]-want[i]) > 1e-9 {
+			t.Fatalf("I[%d][%d] = %v, want %v", i, i, p.Inertia[i][i], want[i])
+		}
+		for j := range 3 {
+			if i != j && math.Abs(p.Inertia[i][j]) > 1e-9 {
+				t.Fatalf("off-diagonal I[%d][%d] = %v", i, j, p.Inertia[i][j])
+			}
+		}
+	}
+
+	g.Set(0, 0, 0, 2)
+	heavy := vopl.MassProperties(&g, map[uint8]float64{2: 17})
+	if heavy.Mass != 32 || heavy.CenterOfMass[0] >= 2 {
+		t.Fatalf("density not applied: %+v", heavy)
+	}
+}
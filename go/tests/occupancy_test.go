@@ -0,0 +1,104 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+func TestOccupancy_FromGrid(t *testing.T) {
+	g := makeSmallGrid()
+	o := g.Occupancy()
+	if o.Count() != g.CountNonZero() {
+		t.Fatalf("count %d, grid has %d", o.Count(), g.CountNonZero())
+	}
+	for v := range g.All() {
+		if o.Has(v.X, v.Y, v.Z) != (v.Color != 0) {
+			t.Fatalf("cell %v mismatch", v)
+		}
+	}
+	if o.Has(-1, 0, 0) || o.Has(0, 0, 16) {
+		t.Fatalf("cells outside the grid reported set")
+	}
+	i := 0
+	for c := range o.Cells() {
+		i++
+		if !o.Has(c[0], c[1], c[2]) {
+			t.Fatalf("Cells yielded clear cell %v", c)
+		}
+	}
+	if i != o.Count() {
+		t.Fatalf("Cells yielded %d cells", i)
+	}
+}
+
+func TestOccupancy_SetOps(t *testing.T) {
+	var a, b vopl.Occupancy
+	a.Set(1, 2, 3, true)
+	a.Set(4, 5, 6, true)
+	b.Set(4, 5, 6, true)
+	b.Set(15, 15, 15, true)
+	if a.And(b).Count() != 1 || a.Or(b).Count() != 3 || a.Xor(b).Count() != 2 {
+		t.Fatalf("and/or/xor counts wrong")
+	}
+	if d := a.AndNot(b); d.Count() != 1 || !d.Has(1, 2, 3) {
+		t.Fatalf("andnot wrong")
+	}
+	if a.Not().Count() != 4096-2 || !a.Not().Not().Or(vopl.Occupancy{}).And(a).Xor(a).IsEmpty() {
+		t.Fatalf("not wrong")
+	}
+	if a.Set(16, 0, 0, true) || !a.Set(1, 2, 3, false) || a.Has(1, 2, 3) {
+		t.Fatalf("set wrong")
+	}
+}
+
+func TestOccupancy_Faces(t *testing.T) {
+	var g vopl.VoxelGrid
+	g.FillBox(0, 0, 0, 3, 1, 1, 1) // a 3x1x1 bar along x at the corner
+	o := g.Occupancy()
+	px := o.Exposed(vopl.FacePosX)
+	if px.Count() != 1 || !px.Has(2, 0, 0) {
+		t.Fatalf("+x faces wrong")
+	}
+	if nz := o.Exposed(vopl.FaceNegZ); nz.Count() != 3 {
+		t.Fatalf("-z faces: %d", nz.Count())
+	}
+	total := 0
+	for f := vopl.FacePosX; f <= vopl.FaceNegZ; f++ {
+		total += o.Exposed(f).Count()
+	}
+	if total != 14 {
+		t.Fatalf("bar has %d exposed faces, want 14", total)
+	}
+	// -y neighbours of the bottom layer lie outside the grid
+	if n := o.Neighbors(vopl.FaceNegY, true); !n.Has(7, 0, 7) || n.Has(7, 1, 7) {
+		t.Fatalf("outside neighbours wrong")
+	}
+	if n := o.Neighbors(vopl.FacePosZ, false); n.Has(0, 0, 0) || n.Has(0, 0, 15) {
+		t.Fatalf("+z neighbours wrong")
+	}
+}
+
+func TestOccupancy_Exterior(t *testing.T) {
+	var g vopl.VoxelGrid
+	g.FillBox(2, 2, 2, 10, 10, 10, 4)
+	g.FillBox(4, 4, 4, 8, 8, 8, 0) // sealed 4³ cavity
+	air := g.Occupancy().Exterior()
+	if air.Count() != 4096-8*8*8 {
+		t.Fatalf("exterior air %d", air.Count())
+	}
+	if air.Has(5, 5, 5) || !air.Has(0, 0, 0) || !air.Has(11, 5, 5) {
+		t.Fatalf("exterior membership wrong")
+	}
+	mask := vopl.ExteriorAir(&g)
+	for v := range g.All() {
+		if mask[v.Y][v.X][v.Z] != air.Has(v.X, v.Y, v.Z) {
+			t.Fatalf("ExteriorAir disagrees at %v", v)
+		}
+	}
+	// one tunnel opens the cavity
+	g.FillBox(6, 6, 0, 7, 7, 4, 0)
+	if air := g.Occupancy().Exterior(); !air.Has(5, 5, 5) {
+		t.Fatalf("opened cavity still sealed")
+	}
+}
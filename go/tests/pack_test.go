@@ -0,0 +1,117 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+// packOf builds a pack whose entries are the given grids saved as .vopl files.
+func packOf(t *testing.T, grids ...*vopl.VoxelGrid) *vopl.Pack {
+	t.Helper()
+	p := &vopl.Pack{}
+	for i, g := range grids {
+		data := vopl.SaveVoplGridToBytes(g)
+		hdr, payload, err := vopl.ParseVOPLHeaderFromBytes(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hdr.PLen = 0 // per-file, not part of the common header
+		p.Header = hdr
+		p.Entries = append(p.Entries, vopl.PackEntry{Name: fmt.Sprintf("%d.vopl", i), Enc: data[5], Payload: payload})
+	}
+	return p
+}
+
+// stairs returns an asymmetric shape, so each symmetry yields a distinct grid.
+func stairs() *vopl.VoxelGrid {
+	var g vopl.VoxelGrid
+	for i := range 6 {
+		g.FillBox(i, 0, 0, i+1, i+1, 3+i%2, uint8(1+i))
+	}
+	g.Set(15, 9, 4, 7)
+	return &g
+}
+
+func TestPackSymmetry_RoundTrip(t *testing.T) {
+	base := stairs()
+	other := wall(5)
+	grids := []*vopl.VoxelGrid{base, base.Rotate90(vopl.AxisY, 1), other, base.Mirror(vopl.AxisX), base}
+	for _, i := range []int{5, 27, 46} {
+		s, _ := vopl.SymmetryFromIndex(i)
+		grids = append(grids, base.Transform(s))
+	}
+	p := packOf(t, grids...)
+
+	sym, err := p.MarshalEx(vopl.LayoutSymmetry, vopl.PackCompNone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := p.MarshalEx(vopl.LayoutRaw, vopl.PackCompNone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// only the base and the wall keep their payloads; the other six become references
+	if len(sym) >= len(raw)/2 {
+		t.Fatalf("symmetry pack is %d bytes, raw %d", len(sym), len(raw))
+	}
+
+	got, comp, err := vopl.UnmarshalPack(sym)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if comp != vopl.PackCompNone || got.Header != p.Header || len(got.Entries) != len(p.Entries) {
+		t.Fatalf("unpacked header or entry count differs")
+	}
+	for i, e := range got.Entries {
+		want := p.Entries[i]
+		if e.Name != want.Name || e.Enc != want.Enc || !bytes.Equal(e.Payload, want.Payload) {
+			t.Fatalf("entry %d not restored exactly", i)
+		}
+	}
+}
+
+func TestPackSymmetry_CompressedAndUnique(t *testing.T) {
+	a, b := stairs(), wall(2)
+	p := packOf(t, a, b)
+	for _, comp := range []vopl.PackCompression{vopl.PackCompZlib, vopl.PackCompZstd} {
+		data, err := p.MarshalEx(vopl.LayoutSymmetry, comp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, c, err := vopl.UnmarshalPack(data)
+		if err != nil || c != comp {
+			t.Fatalf("unpack with %d: %v", comp, err)
+		}
+		for i, e := range got.Entries {
+			if !bytes.Equal(e.Payload, p.Entries[i].Payload) {
+				t.Fatalf("entry %d changed with compression %d", i, comp)
+			}
+		}
+	}
+}
+
+func TestGridTransform_MatchesRotateAndMirror(t *testing.T) {
+	g := stairs()
+	if *g.Transform(vopl.Symmetry{Perm: [3]uint8{0, 1, 2}}) != *g {
+		t.Fatalf("identity changed the grid")
+	}
+	found := false
+	for _, s := range vopl.Symmetries(true) {
+		if *g.Transform(s) == *g.Rotate90(vopl.AxisZ, 1) {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("quarter turn about Z is not among the rotations")
+	}
+	s, _ := vopl.SymmetryFromIndex(1) // flip x
+	if *g.Transform(s) != *g.Mirror(vopl.AxisX) {
+		t.Fatalf("flip x differs from Mirror(AxisX)")
+	}
+	if *g.Transform(s).Transform(s.Inverse()) != *g {
+		t.Fatalf("inverse does not undo the transform")
+	}
+}
@@ -0,0 +1,79 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+func TestRaster_BoxAndHollow(t *testing.T) {
+	var g vopl.VoxelGrid
+	r := vopl.Raster{Grid: &g, Color: 3}
+	if n := r.Box([3]int{1, 1, 1}, [3]int{5, 5, 5}, false); n != 64 {
+		t.Fatalf("solid box wrote %d, want 64", n)
+	}
+	var h vopl.VoxelGrid
+	r.Grid = &h
+	if n := r.Box([3]int{1, 1, 1}, [3]int{5, 5, 5}, true); n != 64-8 {
+		t.Fatalf("hollow box wrote %d, want 56", n)
+	}
+}
+
+func TestRaster_SphereAcrossChunks(t *testing.T) {
+	// A sphere centered on the border x=16 drawn into two chunks must equal the
+	// same sphere drawn into one chunk that contains it entirely.
+	var whole vopl.VoxelGrid
+	wn := vopl.Raster{Grid: &whole, Color: 5}.Sphere([3]float64{8, 8, 8}, 5, true)
+
+	var left, right vopl.VoxelGrid
+	center := [3]float64{16, 8, 8}
+	ln := vopl.Raster{Grid: &left, Color: 5}.Sphere(center, 5, true)
+	rn := vopl.Raster{Grid: &right, Origin: [3]int{16, 0, 0}, Color: 5}.Sphere(center, 5, true)
+	if ln+rn != wn {
+		t.Fatalf("split sphere wrote %d+%d, whole wrote %d", ln, rn, wn)
+	}
+	for y := range vopl.Height {
+		for z := range vopl.Depth {
+			for x := range 8 {
+				if left.Get(8+x, y, z) != whole.Get(x, y, z) || right.Get(x, y, z) != whole.Get(8+x, y, z) {
+					t.Fatalf("split sphere differs at x=%d y=%d z=%d", x, y, z)
+				}
+			}
+		}
+	}
+}
+
+func TestRaster_LineConeTriangle(t *testing.T) {
+	var g vopl.VoxelGrid
+	r := vopl.Raster{Grid: &g, Color: 1}
+	if n := r.Line([3]int{0, 0, 0}, [3]int{15, 7, 3}); n != 16 {
+		t.Fatalf("line wrote %d cells, want 16", n)
+	}
+	if g.Get(15, 7, 3) != 1 || g.Get(0, 0, 0) != 1 {
+		t.Fatalf("line endpoints missing")
+	}
+	if n := r.Line([3]int{-5, 0, 0}, [3]int{5, 0, 0}); n != 6 {
+		t.Fatalf("clipped line wrote %d, want 6", n)
+	}
+
+	var c vopl.VoxelGrid
+	r.Grid = &c
+	cone := r.Cone([3]float64{8, 0, 8}, vopl.AxisY, 6, 10, false)
+	var cyl vopl.VoxelGrid
+	r.Grid = &cyl
+	cylinder := r.Cylinder([3]float64{8, 0, 8}, vopl.AxisY, 6, 10, false)
+	if cone == 0 || cone >= cylinder {
+		t.Fatalf("cone (%d) should be non-empty and smaller than cylinder (%d)", cone, cylinder)
+	}
+
+	var tri vopl.VoxelGrid
+	r.Grid = &tri
+	if n := r.Triangle([3]float64{0.5, 2.5, 0.5}, [3]float64{10.5, 2.5, 0.5}, [3]float64{0.5, 2.5, 10.5}, false); n == 0 {
+		t.Fatalf("triangle wrote nothing")
+	}
+	for v := range tri.Occupied() {
+		if v.Y != 2 {
+			t.Fatalf("flat triangle leaked to y=%d", v.Y)
+		}
+	}
+}
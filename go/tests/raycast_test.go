@@ -0,0 +1,63 @@
+package test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+func TestRaycast_SingleChunk(t *testing.T) {
+	var g vopl.VoxelGrid
+	g.Set(5, 2, 2, 7)
+	hit, ok, err := vopl.Raycast(&g, [3]float64{0.5, 2.5, 2.5}, [3]float64{1, 0, 0}, 20)
+	if err != nil || !ok {
+		t.Fatalf("expected hit (err=%v)", err)
+	}
+	if hit.Voxel != [3]int{5, 2, 2} || hit.Normal != [3]int{-1, 0, 0} || hit.Color != 7 {
+		t.Fatalf("unexpected hit %+v", hit)
+	}
+	if math.Abs(hit.Distance-4.5) > 1e-9 {
+		t.Fatalf("distance = %v, want 4.5", hit.Distance)
+	}
+	if _, ok, _ := vopl.Raycast(&g, [3]float64{0.5, 2.5, 2.5}, [3]float64{1, 0, 0}, 4); ok {
+		t.Fatalf("hit beyond maxDist")
+	}
+	// from above, diagonal
+	hit, ok, _ = vopl.Raycast(&g, [3]float64{5.5, 10.5, 2.5}, [3]float64{0, -1, 0}, 20)
+	if !ok || hit.Normal != [3]int{0, 1, 0} || math.Abs(hit.Distance-7.5) > 1e-9 {
+		t.Fatalf("top hit %+v", hit)
+	}
+}
+
+func TestRaycast_AcrossChunks(t *testing.T) {
+	var far vopl.VoxelGrid
+	far.Set(2, 0, 0, 4)
+	world := vopl.ChunkMap{{1, 0, 0}: &far}
+	hit, ok, err := vopl.Raycast(world, [3]float64{0.5, 0.5, 0.5}, [3]float64{1, 0, 0}, 64)
+	if err != nil || !ok || hit.Voxel != [3]int{18, 0, 0} {
+		t.Fatalf("cross-chunk hit %+v ok=%v err=%v", hit, ok, err)
+	}
+}
+
+func TestAABB_OverlapAndSweep(t *testing.T) {
+	var g vopl.VoxelGrid
+	g.FillBox(0, 0, 0, 16, 1, 16, 1) // floor
+	box := vopl.AABB{Min: [3]float64{4, 1, 4}, Max: [3]float64{5, 3, 5}}
+	if vopl.Overlaps(&g, box) {
+		t.Fatalf("box resting on the floor should not overlap it")
+	}
+	sunk := box
+	sunk.Min[1] -= 0.25
+	if n := len(vopl.OverlappingVoxels(&g, sunk)); n != 1 {
+		t.Fatalf("sunk box overlaps %d voxels, want 1", n)
+	}
+	lifted := vopl.AABB{Min: [3]float64{4.5, 5, 4.5}, Max: [3]float64{5.5, 7, 5.5}}
+	hit, ok := vopl.SweepAABB(&g, lifted, [3]float64{0, -8, 0})
+	if !ok || hit.Normal != [3]int{0, 1, 0} || math.Abs(hit.Time-0.5) > 1e-9 {
+		t.Fatalf("sweep hit %+v ok=%v", hit, ok)
+	}
+	if _, ok := vopl.SweepAABB(&g, lifted, [3]float64{3, 0, 0}); ok {
+		t.Fatalf("horizontal sweep above the floor should not hit")
+	}
+}
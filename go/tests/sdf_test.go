@@ -0,0 +1,92 @@
+package test
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+// boxDistance is the distance from p to the axis-aligned unit cube at voxel v.
+func boxDistance(p [3]float64, v [3]int) float64 {
+	var sum float64
+	for i := range 3 {
+		d := math.Max(math.Max(float64(v[i])-p[i], p[i]-float64(v[i]+1)), 0)
+		sum += d * d
+	}
+	return math.Sqrt(sum)
+}
+
+func TestSDF_MatchesBoxDistance(t *testing.T) {
+	var g vopl.VoxelGrid
+	voxels := [][3]int{{3, 4, 5}, {9, 2, 11}, {12, 12, 3}, {6, 10, 8}}
+	for _, v := range voxels {
+		g.Set(v[0], v[1], v[2], 1)
+	}
+	sdf, err := vopl.ComputeSDF(&g, 2)
+	if err != nil {
+		t.Fatalf("ComputeSDF: %v", err)
+	}
+	for z := range sdf.Size {
+		for y := range sdf.Size {
+			for x := range sdf.Size {
+				if g.Get(x/2, y/2, z/2) != 0 {
+					continue
+				}
+				p := [3]float64{(float64(x) + 0.5) / 2, (float64(y) + 0.5) / 2, (float64(z) + 0.5) / 2}
+				want := math.Inf(1)
+				for _, v := range voxels {
+					want = math.Min(want, boxDistance(p, v))
+				}
+				if got := sdf.At(x, y, z); math.Abs(float64(got)-want) > 1e-5 {
+					t.Fatalf("sample (%d,%d,%d) = %v, want %v", x, y, z, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestSDF_SingleVoxel(t *testing.T) {
+	var g vopl.VoxelGrid
+	g.Set(8, 8, 8, 1)
+	sdf, err := vopl.ComputeSDF(&g, 1)
+	if err != nil {
+		t.Fatalf("ComputeSDF: %v", err)
+	}
+	if sdf.Size != 16 || len(sdf.Data) != 16*16*16 {
+		t.Fatalf("unexpected size %d", sdf.Size)
+	}
+	if v := sdf.At(8, 8, 8); v >= 0 {
+		t.Fatalf("inside sample = %v, want negative", v)
+	}
+	if v := sdf.At(11, 8, 8); math.Abs(float64(v)-2.5) > 1e-6 {
+		t.Fatalf("sample 3 cells away = %v, want 2.5", v)
+	}
+	// the nearest point of the voxel is its edge, half a voxel away along x and y
+	if v := sdf.At(9, 9, 8); math.Abs(float64(v)-math.Sqrt(0.5)) > 1e-6 {
+		t.Fatalf("diagonal sample = %v, want %v", v, math.Sqrt(0.5))
+	}
+	if v, want := sdf.At(10, 10, 10), math.Sqrt(3*1.5*1.5); math.Abs(float64(v)-want) > 1e-6 {
+		t.Fatalf("corner diagonal sample = %v, want %v", v, want)
+	}
+
+	var full vopl.VoxelGrid
+	full.FillBox(0, 0, 0, 16, 16, 16, 1)
+	fs, _ := vopl.ComputeSDF(&full, 1)
+	if v := fs.At(0, 5, 5); math.Abs(float64(v)+0.5) > 1e-6 {
+		t.Fatalf("edge sample of full grid = %v, want -0.5", v)
+	}
+
+	ss, err := vopl.ComputeSDF(&g, 2)
+	if err != nil || ss.Size != 32 {
+		t.Fatalf("supersampled size %d err %v", ss.Size, err)
+	}
+	var raw, ktx bytes.Buffer
+	if err := ss.WriteRaw(&raw); err != nil || raw.Len() != 32*32*32*4 {
+		t.Fatalf("raw len %d err %v", raw.Len(), err)
+	}
+	if err := ss.WriteKTX(&ktx); err != nil || ktx.Len() != 64+4+raw.Len() {
+		t.Fatalf("ktx len %d err %v", ktx.Len(), err)
+	}
+}
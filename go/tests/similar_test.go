@@ -0,0 +1,142 @@
+package test
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/voxelsplace/vopl/go/utils"
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+// wall returns a 16x8 wall at z=0 with 128 voxels of color c.
+func wall(c uint8) *vopl.VoxelGrid {
+	var g vopl.VoxelGrid
+	g.FillBox(0, 0, 0, 16, 8, 1, c)
+	return &g
+}
+
+func TestSimilarity_Exact(t *testing.T) {
+	a := wall(3)
+	if s := vopl.Similarity(a, a); s != 1 {
+		t.Fatalf("self similarity = %v", s)
+	}
+	b := *a
+	b.Set(0, 0, 0, 0) // remove one: 127 shared of 128
+	if s := vopl.Similarity(a, &b); math.Abs(s-127.0/128) > 1e-12 {
+		t.Fatalf("similarity after removal = %v", s)
+	}
+	b.Set(1, 0, 0, 4) // recolor one: 126 shared, union 126 + 1 removed + 2 for the recolored cell
+	if s := vopl.Similarity(a, &b); math.Abs(s-126.0/129) > 1e-12 {
+		t.Fatalf("similarity after recolor = %v", s)
+	}
+	var e1, e2 vopl.VoxelGrid
+	if vopl.Similarity(&e1, &e2) != 1 || vopl.Similarity(&e1, a) != 0 {
+		t.Fatalf("empty grid similarity wrong")
+	}
+}
+
+func TestFingerprint_Estimate(t *testing.T) {
+	a := wall(3)
+	b := *a
+	b.Set(5, 5, 0, 0)
+	fa, fb := vopl.ComputeFingerprint(a), vopl.ComputeFingerprint(&b)
+	if fa != vopl.ComputeFingerprint(wall(3)) {
+		t.Fatalf("fingerprint is not deterministic")
+	}
+	if est := fa.Estimate(fb); est < 0.85 {
+		t.Fatalf("near-duplicate estimate = %v", est)
+	}
+	if est := fa.Estimate(vopl.ComputeFingerprint(wall(9))); est > 0.1 {
+		t.Fatalf("unrelated estimate = %v", est)
+	}
+}
+
+func TestClusterBySimilarity(t *testing.T) {
+	base := wall(3)
+	v1, v2 := *base, *base
+	v1.Set(0, 0, 0, 0)
+	v2.Set(15, 7, 0, 0)
+	v2.Set(14, 7, 0, 0)
+	other := wall(9)
+	otherVariant := *other
+	otherVariant.Set(3, 3, 0, 5)
+	grids := []*vopl.VoxelGrid{base, other, &v1, makeSmallGrid(), &v2, &otherVariant}
+	clusters := vopl.ClusterBySimilarity(grids, 0.95)
+	if len(clusters) != 2 {
+		t.Fatalf("clusters = %+v", clusters)
+	}
+	want := [][]int{{0, 2, 4}, {1, 5}}
+	for i, c := range clusters {
+		if len(c.Members) != len(want[i]) {
+			t.Fatalf("cluster %d = %v, want %v", i, c.Members, want[i])
+		}
+		for j := range want[i] {
+			if c.Members[j] != want[i][j] {
+				t.Fatalf("cluster %d = %v, want %v", i, c.Members, want[i])
+			}
+		}
+	}
+	// the weakest link of {base, v1, v2} is base-v2 (126/128)
+	if math.Abs(clusters[0].MinSimilarity-126.0/128) > 1e-12 {
+		t.Fatalf("MinSimilarity = %v", clusters[0].MinSimilarity)
+	}
+	if c := vopl.ClusterBySimilarity(grids, 1); len(c) != 0 {
+		t.Fatalf("threshold 1 should only group identical grids: %+v", c)
+	}
+}
+
+func TestClusterBySimilarity_Duplicates(t *testing.T) {
+	// 3000 identical walls would be 4.5 million pairwise checks if they shared buckets
+	// as separate grids
+	base := wall(3)
+	variant := *base
+	variant.Set(0, 0, 0, 0)
+	grids := []*vopl.VoxelGrid{makeSmallGrid()}
+	for range 3000 {
+		g := *base
+		grids = append(grids, &g)
+	}
+	grids = append(grids, &variant, makeSmallGrid())
+
+	clusters := vopl.ClusterBySimilarity(grids, 0.95)
+	if len(clusters) != 2 {
+		t.Fatalf("got %d clusters", len(clusters))
+	}
+	if c := clusters[0]; len(c.Members) != 2 || c.Members[1] != 3002 || c.MinSimilarity != 1 {
+		t.Fatalf("small grid cluster = %+v", c)
+	}
+	walls := clusters[1]
+	if len(walls.Members) != 3001 || walls.Members[0] != 1 || walls.Members[3000] != 3001 {
+		t.Fatalf("wall cluster has %d members", len(walls.Members))
+	}
+	if math.Abs(walls.MinSimilarity-127.0/128) > 1e-12 {
+		t.Fatalf("MinSimilarity = %v", walls.MinSimilarity)
+	}
+	exact := vopl.ClusterBySimilarity(grids, 1)
+	if len(exact) != 2 || len(exact[1].Members) != 3000 || exact[1].MinSimilarity != 1 {
+		t.Fatalf("threshold 1 clusters = %d", len(exact))
+	}
+}
+
+func TestFindSimilar_Directory(t *testing.T) {
+	dir := t.TempDir()
+	a := wall(3)
+	b := *a
+	b.Set(2, 2, 0, 0)
+	for name, g := range map[string]*vopl.VoxelGrid{"a.vopl": a, "b.vopl": &b, "c.vopl": makeSmallGrid()} {
+		if err := vopl.SaveVoplGrid(g, filepath.Join(dir, name)); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+	}
+	clusters, err := utils.FindSimilar(dir, 0.9)
+	if err != nil {
+		t.Fatalf("FindSimilar: %v", err)
+	}
+	if len(clusters) != 1 || len(clusters[0].Members) != 2 || clusters[0].Members[0] != "a.vopl" || clusters[0].Members[1] != "b.vopl" {
+		t.Fatalf("clusters = %+v", clusters)
+	}
+	if _, err := utils.FindSimilar(dir, 0); err == nil {
+		t.Fatalf("expected error for threshold 0")
+	}
+}
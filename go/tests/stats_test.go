@@ -0,0 +1,30 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+func TestStats_SingleBox(t *testing.T) {
+	var g vopl.VoxelGrid
+	g.FillBox(2, 0, 3, 4, 1, 4, 7) // 2x1x1 bar
+	g.Set(10, 10, 10, 8)
+	s := vopl.Stats(&g)
+	if s.VoxelCount != 3 || s.Histogram[7] != 2 || s.Histogram[8] != 1 {
+		t.Fatalf("unexpected counts: %+v", s)
+	}
+	if s.Bounds != (vopl.Box{Min: [3]int{2, 0, 3}, Max: [3]int{11, 11, 11}}) {
+		t.Fatalf("bounds = %+v", s.Bounds)
+	}
+	if s.ExposedFaces != 10+6 {
+		t.Fatalf("exposed faces = %d, want 16", s.ExposedFaces)
+	}
+	if s.QuadCount != 6+6 {
+		t.Fatalf("quads = %d, want 12", s.QuadCount)
+	}
+	var empty vopl.VoxelGrid
+	if _, ok := empty.Bounds(); ok {
+		t.Fatalf("empty grid should have no bounds")
+	}
+}
@@ -0,0 +1,186 @@
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/voxelsplace/vopl/go/utils"
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+func TestSymmetry_Group(t *testing.T) {
+	size := [3]int{2, 3, 5}
+	all := vopl.Symmetries(false)
+	if len(all) != 48 || len(vopl.Symmetries(true)) != 24 {
+		t.Fatalf("got %d symmetries", len(all))
+	}
+	seen := map[[2][3]int]bool{}
+	for i, s := range all {
+		if s.Index() != i {
+			t.Fatalf("Index() = %d, want %d", s.Index(), i)
+		}
+		// the images of two corners identify the symmetry
+		key := [2][3]int{s.Apply([3]int{0, 0, 0}, size), s.Apply([3]int{1, 0, 0}, size)}
+		key[1] = [3]int{key[1][0] + 10*s.Size(size)[0], key[1][1] + 10*s.Size(size)[1], key[1][2] + 10*s.Size(size)[2]}
+		if seen[key] {
+			t.Fatalf("symmetry %d duplicates another", i)
+		}
+		seen[key] = true
+		inv := s.Inverse()
+		p := [3]int{1, 2, 3}
+		if got := inv.Apply(s.Apply(p, size), s.Size(size)); got != p {
+			t.Fatalf("symmetry %d: inverse maps back to %v", i, got)
+		}
+	}
+	if _, err := vopl.SymmetryFromIndex(48); err == nil {
+		t.Fatalf("expected error for index 48")
+	}
+}
+
+func TestSymmetry_MatchesGridRotation(t *testing.T) {
+	g := makeSmallGrid()
+	g.Set(1, 2, 3, 9)
+	clip, _ := vopl.CopyRegion(g, vopl.Box{Max: [3]int{16, 16, 16}})
+	want := g.Rotate90(vopl.AxisY, 1)
+	found := false
+	for _, s := range vopl.Symmetries(true) {
+		var out vopl.VoxelGrid
+		tc := clip.Transform(s)
+		tc.Paste(&out, tc.Anchor, false)
+		if out == *want {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("no proper symmetry reproduces Rotate90(Y, 1)")
+	}
+}
+
+// lShape returns an asymmetric 3x2x1 template: an L of color 6.
+func lShape() *vopl.Clipboard {
+	c, _ := vopl.NewClipboard([3]int{3, 2, 1})
+	c.Set(0, 0, 0, 6)
+	c.Set(1, 0, 0, 6)
+	c.Set(2, 0, 0, 6)
+	c.Set(0, 1, 0, 6)
+	return c
+}
+
+func TestTemplate_ExactAndRotated(t *testing.T) {
+	var g vopl.VoxelGrid
+	lShape().Paste(&g, [3]int{4, 5, 6}, true)
+	m := vopl.MatchTemplate(&g, lShape(), vopl.TemplateOptions{AirIsWildcard: true})
+	if len(m) != 1 || m[0].Offset != [3]int{4, 5, 6} || m[0].Symmetry != 0 {
+		t.Fatalf("exact matches = %+v", m)
+	}
+
+	var r vopl.VoxelGrid
+	rot := lShape().Rotate90(vopl.AxisZ, 1)
+	rot.Paste(&r, [3]int{10, 0, 0}, true)
+	if m := vopl.MatchTemplate(&r, lShape(), vopl.TemplateOptions{AirIsWildcard: true}); len(m) != 0 {
+		t.Fatalf("rotated copy matched without rotations: %+v", m)
+	}
+	m = vopl.MatchTemplate(&r, lShape(), vopl.TemplateOptions{AirIsWildcard: true, Rotations: true})
+	if len(m) != 1 || m[0].Offset != rot.Bounds([3]int{10, 0, 0}).Min || m[0].Size != [3]int{2, 3, 1} {
+		t.Fatalf("rotated matches = %+v", m)
+	}
+
+	var mir vopl.VoxelGrid
+	lShape().Mirror(vopl.AxisX).Paste(&mir, [3]int{5, 0, 0}, true)
+	if m := vopl.MatchTemplate(&mir, lShape(), vopl.TemplateOptions{AirIsWildcard: true, Mirrors: true}); len(m) != 1 || m[0].Symmetry != 1 {
+		t.Fatalf("mirrored matches = %+v", m)
+	}
+}
+
+func TestTemplate_AirAndWildcards(t *testing.T) {
+	var g vopl.VoxelGrid
+	lShape().Paste(&g, [3]int{0, 0, 0}, true)
+	g.Set(1, 1, 0, 3) // fills the L's empty corner
+	if m := vopl.MatchTemplate(&g, lShape(), vopl.TemplateOptions{AirIsWildcard: true}); len(m) != 1 {
+		t.Fatalf("air=any should ignore the filled corner: %+v", m)
+	}
+	if m := vopl.MatchTemplate(&g, lShape(), vopl.TemplateOptions{}); len(m) != 0 {
+		t.Fatalf("air=empty should reject the filled corner: %+v", m)
+	}
+	tmpl := lShape()
+	tmpl.Set(2, 0, 0, 63) // wildcard end
+	g.Set(2, 0, 0, 0)
+	if m := vopl.MatchTemplate(&g, tmpl, vopl.TemplateOptions{AirIsWildcard: true, Wildcard: 63}); len(m) != 1 {
+		t.Fatalf("wildcard cell should match anything: %+v", m)
+	}
+	empty, _ := vopl.NewClipboard([3]int{2, 2, 2})
+	if m := vopl.MatchTemplate(&g, empty, vopl.TemplateOptions{AirIsWildcard: true}); len(m) != 0 {
+		t.Fatalf("an all-wildcard template should match nothing")
+	}
+}
+
+func TestTemplate_FindInDirectory(t *testing.T) {
+	dir := t.TempDir()
+	var a, b vopl.VoxelGrid
+	lShape().Paste(&b, [3]int{7, 7, 7}, true)
+	a.Set(0, 0, 0, 6)
+	for name, g := range map[string]*vopl.VoxelGrid{"a.vopl": &a, "b.vopl": &b} {
+		if err := vopl.SaveVoplGrid(g, filepath.Join(dir, name)); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+	}
+	fab := filepath.Join(t.TempDir(), "l.voplfab")
+	if err := vopl.SavePrefab(lShape(), fab); err != nil {
+		t.Fatalf("SavePrefab: %v", err)
+	}
+	tmpl, err := utils.LoadTemplate(fab)
+	if err != nil {
+		t.Fatalf("LoadTemplate: %v", err)
+	}
+	opts, err := utils.ParseTemplateOptions([]string{"rotate", "mirror"})
+	if err != nil {
+		t.Fatalf("ParseTemplateOptions: %v", err)
+	}
+	matches, err := utils.FindTemplate(tmpl, dir, opts)
+	if err != nil {
+		t.Fatalf("FindTemplate: %v", err)
+	}
+	if len(matches) != 1 || matches[0].Name != "b.vopl" || matches[0].Offset != [3]int{7, 7, 7} {
+		t.Fatalf("matches = %+v", matches)
+	}
+	if _, err := utils.ParseTemplateOptions([]string{"air=maybe"}); err == nil {
+		t.Fatalf("expected error for bad air option")
+	}
+}
+
+func TestTemplate_AcrossChunks(t *testing.T) {
+	// the L's foot runs from x=14 to x=16, crossing from chunk (0,0,0) into (1,0,0)
+	w := vopl.NewWorld()
+	lShape().Paste(w, [3]int{14, 3, 5}, true)
+	a := vopl.ChunkCoord{0, 0, 0}
+	if w.Len() != 2 || len(vopl.MatchTemplate(w.Chunk(a), lShape(), vopl.TemplateOptions{AirIsWildcard: true})) != 0 {
+		t.Fatalf("setup: template should be split across two chunks")
+	}
+	m := vopl.MatchTemplateWorld(w, lShape(), vopl.TemplateOptions{AirIsWildcard: true})
+	if len(m) != 1 || m[0].Offset != [3]int{14, 3, 5} {
+		t.Fatalf("world matches = %+v", m)
+	}
+
+	// a placement whose minimum corner lies in a missing chunk below an existing one
+	step, _ := vopl.NewClipboard([3]int{2, 1, 1})
+	step.Set(1, 0, 0, 6) // x=0 is air, a wildcard here
+	r := vopl.NewWorld()
+	r.Set(16, 0, 0, 6)
+	m = vopl.MatchTemplateWorld(r, step, vopl.TemplateOptions{AirIsWildcard: true})
+	if r.Chunk(a) != nil || len(m) != 1 || m[0].Offset != [3]int{15, 0, 0} {
+		t.Fatalf("matches from a missing chunk = %+v", m)
+	}
+
+	dir := t.TempDir()
+	if err := w.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+	matches, err := utils.FindTemplate(lShape(), dir, vopl.TemplateOptions{AirIsWildcard: true})
+	if err != nil {
+		t.Fatalf("FindTemplate: %v", err)
+	}
+	if len(matches) != 1 || matches[0].Name != a.FileName() || matches[0].Offset != [3]int{14, 3, 5} ||
+		matches[0].World == nil || *matches[0].World != [3]int{14, 3, 5} {
+		t.Fatalf("matches = %+v", matches)
+	}
+}
@@ -0,0 +1,97 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+func TestText_SingleGlyph(t *testing.T) {
+	var g vopl.VoxelGrid
+	n, err := vopl.RenderText(&g, [3]int{0, 0, 0}, "I", vopl.TextOptions{Color: 4})
+	if err != nil {
+		t.Fatalf("RenderText: %v", err)
+	}
+	// 'I' is a vertical bar of 7 with serifs top and bottom: 7 + 2*2 pixels.
+	if n != 11 || g.CountNonZero() != 11 {
+		t.Fatalf("wrote %d (count %d), want 11", n, g.CountNonZero())
+	}
+	for y := range vopl.GlyphHeight {
+		if g.Get(2, y, 0) != 4 {
+			t.Fatalf("missing stem cell at y=%d", y)
+		}
+	}
+	if g.Get(1, 6, 0) != 4 || g.Get(1, 3, 0) != 0 {
+		t.Fatalf("unexpected serif layout")
+	}
+}
+
+func TestText_OrientationDepthScale(t *testing.T) {
+	var g vopl.VoxelGrid
+	opts := vopl.TextOptions{Right: [3]int{0, 0, -1}, Up: [3]int{0, 1, 0}, Depth: 3, Scale: 2, Color: 1}
+	n, err := vopl.RenderText(&g, [3]int{0, 0, 15}, "-", opts)
+	if err != nil {
+		t.Fatalf("RenderText: %v", err)
+	}
+	// '-' is one row of 5 pixels at glyph row 3 (from the top): 5*4 cells per layer.
+	if n != 5*4*3 {
+		t.Fatalf("wrote %d, want 60", n)
+	}
+	// right = -z, up = +y, so the extrusion runs along (-z)×(+y) = +x.
+	b, ok := g.Bounds()
+	if !ok || b.Min != [3]int{0, 6, 6} || b.Max != [3]int{3, 8, 16} {
+		t.Fatalf("bounds = %+v", b)
+	}
+}
+
+func TestText_ChunkMapSpansChunks(t *testing.T) {
+	m := vopl.ChunkMap{}
+	n, err := vopl.RenderText(m, [3]int{12, 0, 0}, "HI", vopl.TextOptions{Color: 2})
+	if err != nil {
+		t.Fatalf("RenderText: %v", err)
+	}
+	total := 0
+	for _, g := range m {
+		total += g.CountNonZero()
+	}
+	if len(m) != 2 || total != n {
+		t.Fatalf("chunks=%d total=%d n=%d", len(m), total, n)
+	}
+	// second glyph starts 6 columns to the right; its bottom serif is at column 1 (x = 19)
+	if m.Get(19, 0, 0) != 2 {
+		t.Fatalf("expected 'I' serif at x=19")
+	}
+}
+
+func TestText_WrapAndSize(t *testing.T) {
+	opts := vopl.TextOptions{Wrap: 16}
+	lines := vopl.LayoutText("ab cd efghij", opts)
+	want := []string{"ab", "cd", "ef", "gh", "ij"}
+	if len(lines) != len(want) {
+		t.Fatalf("lines = %q, want %q", lines, want)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Fatalf("lines = %q, want %q", lines, want)
+		}
+	}
+	w, h := vopl.TextSize("ab\nc", vopl.TextOptions{Scale: 2})
+	if w != 22 || h != 30 {
+		t.Fatalf("TextSize = %d,%d, want 22,30", w, h)
+	}
+	if got := vopl.LayoutText("é\t", vopl.TextOptions{}); got[0] != "??" {
+		t.Fatalf("non-ASCII not replaced: %q", got)
+	}
+}
+
+func TestText_ParsePlane(t *testing.T) {
+	r, u, err := vopl.ParseTextPlane("x,-z")
+	if err != nil || r != [3]int{1, 0, 0} || u != [3]int{0, 0, -1} {
+		t.Fatalf("ParseTextPlane = %v %v %v", r, u, err)
+	}
+	for _, s := range []string{"x,x", "y,-y", "x", "x,w"} {
+		if _, _, err := vopl.ParseTextPlane(s); err == nil {
+			t.Errorf("ParseTextPlane(%q) succeeded", s)
+		}
+	}
+}
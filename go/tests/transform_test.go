@@ -0,0 +1,61 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/voxelsplace/vopl/go/utils"
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+func TestTransform_RotateFourTimesIsIdentity(t *testing.T) {
+	g := makeSmallGrid()
+	for _, axis := range []vopl.Axis{vopl.AxisX, vopl.AxisY, vopl.AxisZ} {
+		r := g
+		for range 4 {
+			r = r.Rotate90(axis, 1)
+		}
+		if *r != *g {
+			t.Fatalf("4 quarter turns about %s changed the grid", axis)
+		}
+		if *g.Rotate90(axis, 1).Rotate90(axis, -1) != *g {
+			t.Fatalf("rotate +1/-1 about %s is not identity", axis)
+		}
+	}
+}
+
+func TestTransform_RotateDirection(t *testing.T) {
+	var g vopl.VoxelGrid
+	g.Set(15, 0, 0, 3) // +X extreme
+	r := g.Rotate90(vopl.AxisZ, 1)
+	if r.Get(15, 15, 0) != 3 {
+		t.Fatalf("rotz:1 should move +X to +Y")
+	}
+	r = g.Rotate90(vopl.AxisY, 1)
+	if r.Get(0, 0, 0) != 3 {
+		t.Fatalf("roty:1 should move +X to -Z")
+	}
+}
+
+func TestTransform_MirrorAndShift(t *testing.T) {
+	var g vopl.VoxelGrid
+	g.Set(0, 1, 2, 4)
+	if g.Mirror(vopl.AxisX).Get(15, 1, 2) != 4 {
+		t.Fatalf("mirrorx misplaced voxel")
+	}
+	if s := g.Shift(-1, 0, 0, vopl.ShiftClip); s.CountNonZero() != 0 {
+		t.Fatalf("clip shift should drop voxel")
+	}
+	if g.Shift(-1, 0, 0, vopl.ShiftWrap).Get(15, 1, 2) != 4 {
+		t.Fatalf("wrap shift should re-enter on opposite side")
+	}
+	out, err := utils.ApplyTransformOps(&g, []string{"mirrorx", "shift:-15,0,0", "rotx:2", "rotx:-2"})
+	if err != nil {
+		t.Fatalf("ApplyTransformOps: %v", err)
+	}
+	if *out != g {
+		t.Fatalf("op chain should round trip")
+	}
+	if _, err := utils.ApplyTransformOps(&g, []string{"spin"}); err == nil {
+		t.Fatalf("expected error for unknown op")
+	}
+}
@@ -0,0 +1,148 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/voxelsplace/vopl/go/utils"
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+func TestWorld_ApplyUpdates(t *testing.T) {
+	w := vopl.NewWorld()
+	w.Set(0, 0, 0, 3) // chunk (0,0,0)
+	w.Set(16, 0, 0, 4)
+	a, b := vopl.ChunkCoord{0, 0, 0}, vopl.ChunkCoord{1, 0, 0}
+	c := vopl.ChunkCoord{0, 1, 2}
+	up := vopl.Updates{
+		c.ID(): {"5": 9, "6": 9},
+		b.ID(): {"0": 0},
+		a.ID(): {"1": 7, "9999": 1}, // out-of-range index is ignored
+	}
+	results, err := w.ApplyUpdates(up)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []vopl.ChunkUpdate{
+		{ID: a.ID(), Coord: a, Written: 1, Voxels: 2},
+		{ID: b.ID(), Coord: b, Written: 1, Voxels: 0, Removed: true},
+		{ID: c.ID(), Coord: c, Written: 2, Voxels: 2, Created: true},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("results %+v", results)
+	}
+	for i := range want {
+		if results[i] != want[i] {
+			t.Fatalf("result %d = %+v, want %+v", i, results[i], want[i])
+		}
+	}
+	if w.Len() != 2 || w.Get(1, 0, 0) != 7 || w.Get(5, 16, 32) != 9 || w.Chunk(b) != nil {
+		t.Fatalf("world not updated as expected")
+	}
+	if _, err := w.ApplyUpdates(vopl.Updates{"x": {"0": 1}}); err == nil {
+		t.Fatalf("expected an error for an invalid chunk id")
+	}
+}
+
+func TestUpdateVOPL_MultiChunk(t *testing.T) {
+	a, b := vopl.ChunkCoord{0, 0, 0}, vopl.ChunkCoord{3, 0, 1}
+	updates := []byte(`{"` + a.ID() + `": {"0": 1}, "` + b.ID() + `": {"15": 19, "3840": 13}}`)
+
+	dir := filepath.Join(t.TempDir(), "world")
+	w := vopl.NewWorld()
+	w.Set(1, 1, 1, 2)
+	if err := w.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := utils.RunUpdateVOPL(updates, dir, dir); err != nil {
+		t.Fatalf("RunUpdateVOPL on a directory: %v", err)
+	}
+	got, err := vopl.LoadWorld(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Len() != 2 || got.Get(0, 0, 0) != 1 || got.Get(1, 1, 1) != 2 || got.Get(63, 0, 16) != 19 || got.Get(48, 0, 31) != 13 {
+		t.Fatalf("directory world not updated")
+	}
+
+	pack := filepath.Join(t.TempDir(), "world.voplpack")
+	if err := got.Save(pack); err != nil {
+		t.Fatal(err)
+	}
+	if err := utils.RunUpdateVOPL([]byte(`{"`+b.ID()+`": {"15": 0, "3840": 0}}`), pack, pack); err != nil {
+		t.Fatalf("RunUpdateVOPL on a pack: %v", err)
+	}
+	if got, err = vopl.LoadWorld(pack); err != nil || got.Len() != 1 {
+		t.Fatalf("emptied chunk kept in pack: %v", err)
+	}
+
+	// a single file takes the chunk its name selects, and refuses to guess otherwise
+	var empty vopl.VoxelGrid
+	named := filepath.Join(t.TempDir(), b.FileName())
+	if err := vopl.SaveVoplGrid(&empty, named); err != nil {
+		t.Fatal(err)
+	}
+	if err := utils.RunUpdateVOPL(updates, named, named); err != nil {
+		t.Fatalf("RunUpdateVOPL on %s: %v", b.FileName(), err)
+	}
+	if g, _ := vopl.LoadVoplGrid(named); g.Get(15, 0, 0) != 19 || g.Get(0, 0, 0) != 0 {
+		t.Fatalf("wrong chunk applied to the named file")
+	}
+	plain := filepath.Join(t.TempDir(), "plain.vopl")
+	if err := vopl.SaveVoplGrid(&empty, plain); err != nil {
+		t.Fatal(err)
+	}
+	if err := utils.RunUpdateVOPL(updates, plain, plain); err == nil {
+		t.Fatalf("expected an error for a multi-chunk document on an unnamed file")
+	}
+}
+
+func TestUpdateVOPL_PackKeepsFormat(t *testing.T) {
+	g := stairs()
+	p := &vopl.Pack{}
+	for _, name := range []string{"007.vopl", "1.vopl"} {
+		data := vopl.SaveVoplGridToBytesWithBPP(g, 4)
+		hdr, payload, err := vopl.ParseVOPLHeaderFromBytes(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hdr.PLen = 0
+		p.Header = hdr
+		p.Entries = append(p.Entries, vopl.PackEntry{Name: name, Enc: data[5], Payload: payload})
+	}
+	data, err := p.MarshalEx(vopl.LayoutSymmetry, vopl.PackCompZstd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	pack := filepath.Join(dir, "world.voplpack")
+	if err := os.WriteFile(pack, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := utils.RunUpdateVOPL([]byte(`{"7": {"4095": 3}}`), pack, pack); err != nil {
+		t.Fatalf("RunUpdateVOPL: %v", err)
+	}
+	data, err = os.ReadFile(pack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, layout, comp, err := vopl.UnmarshalPackEx(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if layout != vopl.LayoutSymmetry || comp != vopl.PackCompZstd || got.Header != p.Header {
+		t.Fatalf("pack re-encoded as layout %d, compression %d, header %+v", layout, comp, got.Header)
+	}
+	if len(got.Entries) != 2 || got.Entries[0].Name != "1.vopl" || got.Entries[1].Name != "007.vopl" {
+		t.Fatalf("entries renamed: %+v", got.Entries)
+	}
+
+	out := filepath.Join(dir, "out.vopl")
+	if err := utils.RunUpdateVOPL([]byte(`{"7": {"0": 1}}`), pack, out); err == nil {
+		t.Fatalf("expected an error for a .vopl output of a world")
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Fatalf("%s was created: %v", out, err)
+	}
+}
@@ -0,0 +1,49 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+func TestChunkID_RoundTrip(t *testing.T) {
+	c := vopl.ChunkCoord{24648, 15360, 30792}
+	if c.ID() != "26304528517632" {
+		t.Fatalf("ID = %s", c.ID())
+	}
+	got, err := vopl.ParseChunkFileName("26304528517632.vopl")
+	if err != nil || got != c {
+		t.Fatalf("ParseChunkFileName = %v, %v", got, err)
+	}
+	cc, local := vopl.ChunkOf(-1, 17, 32)
+	if cc != (vopl.ChunkCoord{-1, 1, 2}) || local != [3]int{15, 1, 0} {
+		t.Fatalf("ChunkOf = %v %v", cc, local)
+	}
+}
+
+func TestUpscale_SplitsIntoChunks(t *testing.T) {
+	var g vopl.VoxelGrid
+	g.Set(0, 0, 0, 2)
+	g.Set(15, 15, 15, 3)
+	chunks, err := vopl.Upscale(&g, 4, vopl.ChunkCoord{})
+	if err != nil {
+		t.Fatalf("Upscale: %v", err)
+	}
+	if len(chunks) != 2 {
+		t.Fatalf("got %d chunks, want 2", len(chunks))
+	}
+	first := chunks[vopl.ChunkCoord{0, 0, 0}]
+	last := chunks[vopl.ChunkCoord{3, 3, 3}]
+	if first == nil || last == nil {
+		t.Fatalf("missing expected chunks")
+	}
+	if first.CountNonZero() != 64 || first.Get(3, 3, 3) != 2 || first.Get(4, 0, 0) != 0 {
+		t.Fatalf("first chunk block wrong")
+	}
+	if last.CountNonZero() != 64 || last.Get(12, 12, 12) != 3 || last.Get(15, 15, 15) != 3 {
+		t.Fatalf("last chunk block wrong")
+	}
+	if _, err := vopl.Upscale(&g, 0, vopl.ChunkCoord{}); err == nil {
+		t.Fatalf("expected error for factor 0")
+	}
+}
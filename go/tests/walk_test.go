@@ -0,0 +1,145 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/voxelsplace/vopl/go/utils"
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+// floorGrid returns a chunk with a solid floor at y=0, so feet stand at y=1.
+func floorGrid() *vopl.VoxelGrid {
+	var g vopl.VoxelGrid
+	g.FillBox(0, 0, 0, 16, 1, 16, 1)
+	return &g
+}
+
+var chunkBox = vopl.Box{Max: [3]int{16, 16, 16}}
+
+func TestWalkable(t *testing.T) {
+	g := floorGrid()
+	g.Set(3, 2, 3, 2)
+	if !vopl.Walkable(g, 4, 1, 4, 2) || vopl.Walkable(g, 4, 2, 4, 2) {
+		t.Fatalf("floor cells misclassified")
+	}
+	if vopl.Walkable(g, 3, 1, 3, 2) || !vopl.Walkable(g, 3, 1, 3, 1) {
+		t.Fatalf("headroom not respected")
+	}
+	cells := vopl.WalkableCells(g, chunkBox, 2)
+	// 256 floor cells minus the one under the block, plus the block's top
+	if len(cells) != 256 || cells[0] != [3]int{0, 1, 0} || cells[len(cells)-1] != [3]int{3, 3, 3} {
+		t.Fatalf("walkable cells: %d, first %v, last %v", len(cells), cells[0], cells[len(cells)-1])
+	}
+}
+
+func TestFindPath_StepAndDrop(t *testing.T) {
+	g := floorGrid()
+	g.FillBox(5, 1, 0, 6, 3, 16, 2) // two-high wall across z
+	opts := vopl.DefaultWalkOptions()
+
+	if _, ok, err := vopl.FindPath(g, chunkBox, [3]int{0, 1, 0}, [3]int{10, 1, 0}, opts); err != nil || ok {
+		t.Fatalf("climbed a two-high wall with step 1: ok=%v err=%v", ok, err)
+	}
+	opts.StepUp = 2
+	path, ok, err := vopl.FindPath(g, chunkBox, [3]int{0, 1, 0}, [3]int{10, 1, 0}, opts)
+	if err != nil || !ok {
+		t.Fatalf("no path with step 2: %v", err)
+	}
+	if len(path) != 11 || path[5] != [3]int{5, 3, 0} {
+		t.Fatalf("unexpected path %v", path)
+	}
+	for i := 1; i < len(path); i++ {
+		d := [3]int{path[i][0] - path[i-1][0], path[i][1] - path[i-1][1], path[i][2] - path[i-1][2]}
+		if abs(d[0])+abs(d[2]) != 1 || d[1] > opts.StepUp || -d[1] > opts.Drop {
+			t.Fatalf("invalid move %v -> %v", path[i-1], path[i])
+		}
+	}
+
+	tower := floorGrid()
+	tower.FillBox(0, 1, 0, 2, 6, 2, 3) // feet at y=6, five above the floor
+	opts = vopl.DefaultWalkOptions()
+	if _, ok, _ := vopl.FindPath(tower, chunkBox, [3]int{0, 6, 0}, [3]int{5, 1, 5}, opts); ok {
+		t.Fatalf("dropped five with drop 3")
+	}
+	opts.Drop = 5
+	if path, ok, _ := vopl.FindPath(tower, chunkBox, [3]int{0, 6, 0}, [3]int{5, 1, 5}, opts); !ok || len(path) != 11 {
+		t.Fatalf("drop 5 path: ok=%v len=%d", ok, len(path))
+	}
+	if _, _, err := vopl.FindPath(tower, chunkBox, [3]int{0, 5, 0}, [3]int{5, 1, 5}, opts); err == nil {
+		t.Fatalf("expected an error for a start inside the tower")
+	}
+}
+
+func TestFindPath_HeadClearance(t *testing.T) {
+	g := floorGrid()
+	g.FillBox(5, 1, 0, 16, 2, 1, 2) // ledge one high from x=5
+	corridor := vopl.Box{Max: [3]int{16, 16, 1}}
+	start, goal := [3]int{0, 1, 0}, [3]int{6, 2, 0}
+	if _, ok, _ := vopl.FindPath(g, corridor, start, goal, vopl.DefaultWalkOptions()); !ok {
+		t.Fatalf("ledge not climbed")
+	}
+	g.Set(4, 3, 0, 2) // low ceiling over the cell before the ledge
+	if _, ok, _ := vopl.FindPath(g, corridor, start, goal, vopl.DefaultWalkOptions()); ok {
+		t.Fatalf("climbed with the head blocked")
+	}
+}
+
+func TestFindPath_AcrossChunks(t *testing.T) {
+	world := vopl.ChunkMap{{0, 0, 0}: floorGrid(), {1, 0, 0}: floorGrid()}
+	box := vopl.Box{Max: [3]int{32, 16, 16}}
+	path, ok, err := vopl.FindPath(world, box, [3]int{1, 1, 1}, [3]int{30, 1, 1}, vopl.DefaultWalkOptions())
+	if err != nil || !ok || len(path) != 30 {
+		t.Fatalf("cross-chunk path: ok=%v len=%d err=%v", ok, len(path), err)
+	}
+}
+
+func TestBuildNavRegions(t *testing.T) {
+	g := floorGrid()
+	g.FillBox(8, 1, 0, 9, 2, 16, 2) // one-high step across z
+	regions, err := vopl.BuildNavRegions(g, chunkBox, vopl.DefaultWalkOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []vopl.NavRegion{
+		{ID: 0, Box: vopl.Box{Min: [3]int{0, 1, 0}, Max: [3]int{8, 2, 16}}, Links: []int{2}},
+		{ID: 1, Box: vopl.Box{Min: [3]int{9, 1, 0}, Max: [3]int{16, 2, 16}}, Links: []int{2}},
+		{ID: 2, Box: vopl.Box{Min: [3]int{8, 2, 0}, Max: [3]int{9, 3, 16}}, Links: []int{0, 1}},
+	}
+	got, _ := json.Marshal(regions)
+	exp, _ := json.Marshal(want)
+	if !bytes.Equal(got, exp) {
+		t.Fatalf("regions:\n got %s\nwant %s", got, exp)
+	}
+}
+
+func TestRunPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "floor.vopl")
+	if err := vopl.SaveVoplGrid(floorGrid(), path); err != nil {
+		t.Fatal(err)
+	}
+	opts, err := utils.ParsePathOptions([]string{"headroom=3", "margin=0"})
+	if err != nil || opts.Walk.Headroom != 3 || opts.Walk.StepUp != 1 || opts.Margin != 0 {
+		t.Fatalf("options: %+v %v", opts, err)
+	}
+	var buf bytes.Buffer
+	if err := utils.RunPath(path, [3]int{2, 1, 2}, [3]int{5, 1, 4}, opts, &buf); err != nil {
+		t.Fatal(err)
+	}
+	var cells [][3]int
+	if err := json.Unmarshal(buf.Bytes(), &cells); err != nil || len(cells) != 6 {
+		t.Fatalf("path output %q: %v", buf.String(), err)
+	}
+	if _, err := utils.ParseWalkOptions([]string{"margin=2"}); err == nil {
+		t.Fatalf("navmesh options accepted margin")
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,156 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+func TestWorld_LazyChunks(t *testing.T) {
+	w := vopl.NewWorld()
+	if !w.Set(20, 3, 40, 0) || w.Len() != 0 {
+		t.Fatalf("clearing an empty cell created a chunk")
+	}
+	if !w.Set(20, 3, 40, 7) || !w.Set(21, 3, 40, 8) || w.Len() != 1 {
+		t.Fatalf("expected one chunk, got %d", w.Len())
+	}
+	if w.Get(20, 3, 40) != 7 || w.Chunk(vopl.ChunkCoord{1, 0, 2}).Get(4, 3, 8) != 7 {
+		t.Fatalf("write not routed to chunk (1,0,2)")
+	}
+	if w.Set(-1, 0, 0, 1) {
+		t.Fatalf("negative chunk coordinate accepted")
+	}
+	w.Set(15, 0, 0, 1)
+	if got := w.Coords(); len(got) != 2 || got[0] != (vopl.ChunkCoord{0, 0, 0}) {
+		t.Fatalf("coords %v", got)
+	}
+	w.Set(20, 3, 40, 0)
+	if w.Len() != 2 {
+		t.Fatalf("chunk dropped while still occupied")
+	}
+	w.Set(21, 3, 40, 0)
+	if w.Len() != 1 || w.Chunk(vopl.ChunkCoord{1, 0, 2}) != nil {
+		t.Fatalf("emptied chunk kept")
+	}
+
+	w.Chunks().Set(15, 0, 0, 0) // bypasses the world's bookkeeping
+	if w.Len() != 1 || w.Compact() != 1 || w.Len() != 0 {
+		t.Fatalf("compact did not drop the emptied chunk")
+	}
+	if err := w.SetChunk(vopl.ChunkCoord{2, 0, 0}, makeSmallGrid()); err != nil || w.Get(32, 0, 0) == 0 {
+		t.Fatalf("SetChunk: %v", err)
+	}
+	if err := w.SetChunk(vopl.ChunkCoord{2, 0, 0}, &vopl.VoxelGrid{}); err != nil || w.Len() != 0 {
+		t.Fatalf("SetChunk with an empty grid kept the chunk")
+	}
+}
+
+func TestWorld_SaveLoad(t *testing.T) {
+	w := vopl.NewWorld()
+	w.Set(1, 2, 3, 4)
+	w.Set(17, 2, 3, 5)
+	w.Set(40, 33, 70, 6)
+
+	dir := filepath.Join(t.TempDir(), "world")
+	if err := w.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := vopl.LoadWorld(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Len() != 3 || loaded.Get(40, 33, 70) != 6 || loaded.Get(17, 2, 3) != 5 {
+		t.Fatalf("directory round trip lost voxels")
+	}
+
+	// emptying a chunk and saving back deletes its file
+	loaded.Set(17, 2, 3, 0)
+	if err := loaded.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, vopl.ChunkCoord{1, 0, 0}.FileName())); !os.IsNotExist(err) {
+		t.Fatalf("file of the emptied chunk still exists: %v", err)
+	}
+	files, _ := os.ReadDir(dir)
+	if len(files) != 2 {
+		t.Fatalf("directory holds %d files", len(files))
+	}
+
+	pack := filepath.Join(t.TempDir(), "world.voplpack")
+	if err := loaded.Save(pack); err != nil {
+		t.Fatal(err)
+	}
+	fromPack, err := vopl.LoadWorld(pack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromPack.Len() != 2 || fromPack.Get(1, 2, 3) != 4 || fromPack.Get(17, 2, 3) != 0 {
+		t.Fatalf("pack round trip differs")
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad")
+	os.MkdirAll(bad, 0o755)
+	if err := vopl.SaveVoplGrid(makeSmallGrid(), filepath.Join(bad, "house.vopl")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vopl.LoadWorld(bad); err == nil {
+		t.Fatalf("expected an error for a non-chunk file name")
+	}
+}
+
+func TestWorld_SaveWritesOnlyChangedChunks(t *testing.T) {
+	w := vopl.NewWorld()
+	w.Set(1, 2, 3, 4)
+	w.Set(17, 2, 3, 5)
+	w.Set(40, 33, 70, 6)
+	dir := filepath.Join(t.TempDir(), "world")
+	if err := w.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := vopl.LoadWorld(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a chunk the world does not touch keeps its file as is
+	untouched := filepath.Join(dir, vopl.ChunkCoord{0, 0, 0}.FileName())
+	sentinel := []byte("untouched")
+	if err := os.WriteFile(untouched, sentinel, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	loaded.Set(18, 2, 3, 7)
+	loaded.Set(40, 33, 70, 0)
+	if err := loaded.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(untouched); string(b) != string(sentinel) {
+		t.Fatalf("unchanged chunk was rewritten")
+	}
+	g, err := vopl.LoadVoplGrid(filepath.Join(dir, vopl.ChunkCoord{1, 0, 0}.FileName()))
+	if err != nil || g.Get(2, 2, 3) != 7 {
+		t.Fatalf("changed chunk not written: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, vopl.ChunkCoord{2, 2, 4}.FileName())); !os.IsNotExist(err) {
+		t.Fatalf("file of the emptied chunk still exists: %v", err)
+	}
+
+	// writes through Chunks cannot be tracked, so the next save rewrites everything
+	loaded.Chunks()
+	if err := loaded.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+	if g, err := vopl.LoadVoplGrid(untouched); err != nil || g.Get(1, 2, 3) != 4 {
+		t.Fatalf("chunk not rewritten after Chunks: %v", err)
+	}
+
+	// another directory gets every chunk
+	other := filepath.Join(t.TempDir(), "copy")
+	if err := loaded.Save(other); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := os.ReadDir(other); len(files) != 2 {
+		t.Fatalf("new directory holds %d files", len(files))
+	}
+}
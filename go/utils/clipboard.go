@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+// ParsePosition parses a world position "x,y,z".
+func ParsePosition(s string) ([3]int, error) { return parseInt3(s) }
+
+// ParseAnchor parses the "anchor=x,y,z" argument of the copy command.
+func ParseAnchor(arg string) ([3]int, error) {
+	val, ok := strings.CutPrefix(arg, "anchor=")
+	if !ok {
+		return [3]int{}, fmt.Errorf("expected anchor=x,y,z, got %q", arg)
+	}
+	return parseInt3(val)
+}
+
+// ApplyClipboardOps applies rotx|roty|rotz[:N] and mirrorx|mirrory|mirrorz ops (see
+// ApplyTransformOps) to a clipboard, left to right.
+func ApplyClipboardOps(c *vopl.Clipboard, ops []string) (*vopl.Clipboard, error) {
+	out := c
+	for _, op := range ops {
+		a, ok, err := parseAxisOp(op)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("unknown clipboard op: %q", op)
+		}
+		if a.mirror {
+			out = out.Mirror(a.axis)
+		} else {
+			out = out.Rotate90(a.axis, a.turns)
+		}
+	}
+	return out, nil
+}
+
+// RunCopy copies the world box from worldPath (directory or .voplpack of chunk files)
+// into a prefab at outPath. anchor is relative to the box's minimum corner.
+func RunCopy(worldPath string, box vopl.Box, anchor [3]int, outPath string) error {
+	world, err := vopl.LoadWorld(worldPath)
+	if err != nil {
+		return err
+	}
+	clip, err := vopl.CopyRegion(world, box)
+	if err != nil {
+		return err
+	}
+	clip.Anchor = anchor
+	if err := vopl.SavePrefab(clip, outPath); err != nil {
+		return err
+	}
+	fmt.Printf("copy: %dx%dx%d region saved to %s\n", clip.Size[0], clip.Size[1], clip.Size[2], outPath)
+	return nil
+}
+
+// RunPaste pastes the prefab at prefabPath into the world at worldPath (see
+// vopl.LoadWorld) with its anchor at the world position at, after applying ops (see
+// ApplyClipboardOps). The world is created if it does not exist; in a directory only
+// the chunks the paste touches are rewritten, and those it empties are removed.
+func RunPaste(prefabPath, worldPath string, at [3]int, ops []string, airTransparent bool) error {
+	clip, err := vopl.LoadPrefab(prefabPath)
+	if err != nil {
+		return err
+	}
+	if clip, err = ApplyClipboardOps(clip, ops); err != nil {
+		return err
+	}
+	world, err := vopl.LoadWorld(worldPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		world = vopl.NewWorld()
+	} else if err != nil {
+		return err
+	}
+	b := clip.Bounds(at)
+	lo, _ := vopl.ChunkOf(b.Min[0], b.Min[1], b.Min[2])
+	hi, _ := vopl.ChunkOf(b.Max[0]-1, b.Max[1]-1, b.Max[2]-1)
+	if !lo.Valid() || !hi.Valid() {
+		return fmt.Errorf("paste region %v-%v leaves the valid chunk range", b.Min, b.Max)
+	}
+	n := clip.Paste(world, at, airTransparent)
+	if err := world.Save(worldPath); err != nil {
+		return err
+	}
+	fmt.Printf("paste: %d cells written to %s\n", n, worldPath)
+	return nil
+}
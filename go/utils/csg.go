@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+// ParseCSGOptions parses optional key=value arguments for the csg command:
+//
+//	offset=dx,dy,dz   translate the second operand
+//	overlap=a|b|<n>   keep A's color, B's color, or palette index n on overlaps
+func ParseCSGOptions(args []string) (vopl.CSGOptions, error) {
+	var opts vopl.CSGOptions
+	for _, arg := range args {
+		key, val, ok := strings.Cut(arg, "=")
+		if !ok {
+			return opts, fmt.Errorf("expected key=value, got %q", arg)
+		}
+		switch key {
+		case "offset":
+			parts := strings.Split(val, ",")
+			if len(parts) != 3 {
+				return opts, fmt.Errorf("offset must be dx,dy,dz: %q", val)
+			}
+			for i, p := range parts {
+				if _, err := fmt.Sscan(p, &opts.Offset[i]); err != nil {
+					return opts, fmt.Errorf("invalid offset %q: %w", val, err)
+				}
+			}
+		case "overlap":
+			switch val {
+			case "a":
+				opts.Overlap = vopl.OverlapKeepA
+			case "b":
+				opts.Overlap = vopl.OverlapKeepB
+			default:
+				var c int
+				if _, err := fmt.Sscan(val, &c); err != nil || c < 0 || c > 255 {
+					return opts, fmt.Errorf("overlap must be a, b or a palette index: %q", val)
+				}
+				opts.Overlap = vopl.OverlapFixed
+				opts.Color = uint8(c)
+			}
+		default:
+			return opts, fmt.Errorf("unknown csg option: %q", key)
+		}
+	}
+	return opts, nil
+}
+
+// RunCSGVOPL combines two .vopl files with a boolean operation and writes the result.
+func RunCSGVOPL(op vopl.CSGOp, aPath, bPath, outPath string, opts vopl.CSGOptions) error {
+	a, err := vopl.LoadVoplGrid(aPath)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", aPath, err)
+	}
+	b, err := vopl.LoadVoplGrid(bPath)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", bPath, err)
+	}
+	out := vopl.CSG(op, a, b, opts)
+	if err := vopl.SaveVoplGrid(out, outPath); err != nil {
+		return fmt.Errorf("failed to save VOPL: %w", err)
+	}
+	fmt.Printf("csg result: %d voxels\n", out.CountNonZero())
+	if fi, err := os.Stat(outPath); err == nil {
+		fmt.Printf(".vopl saved (%d bytes)\n", fi.Size())
+	}
+	return nil
+}
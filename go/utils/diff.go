@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+// chunkIDFromPath derives a chunk id from a chunk file name ("123.vopl" -> "123").
+func chunkIDFromPath(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// RunDiffVOPL writes the updates JSON that turns aPath into bPath. The chunk id is
+// taken from bPath's file name. When outPath is empty the JSON goes to stdout.
+func RunDiffVOPL(aPath, bPath, outPath string) error {
+	a, err := vopl.LoadVoplGrid(aPath)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", aPath, err)
+	}
+	b, err := vopl.LoadVoplGrid(bPath)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", bPath, err)
+	}
+	chunkID := chunkIDFromPath(bPath)
+	up := vopl.DiffUpdates(chunkID, a, b)
+	data, err := up.Marshal()
+	if err != nil {
+		return err
+	}
+	if outPath == "" {
+		_, err = os.Stdout.Write(append(data, '\n'))
+		return err
+	}
+	if err := os.WriteFile(outPath, data, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("diff: %d changed voxels in chunk %s\n", len(up[chunkID]), chunkID)
+	return nil
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+// RunEval fills one chunk with the formula src (see vopl.Expr) and saves it to outPath.
+// x, y and z are world coordinates of the given chunk.
+func RunEval(src, outPath string, chunk vopl.ChunkCoord) error {
+	expr, err := vopl.ParseExpr(src)
+	if err != nil {
+		return err
+	}
+	var grid vopl.VoxelGrid
+	n := expr.FillGrid(&grid, chunk.Origin())
+	if err := vopl.SaveVoplGrid(&grid, outPath); err != nil {
+		return fmt.Errorf("failed to save %s: %w", outPath, err)
+	}
+	fmt.Printf("eval: %d voxels written to %s\n", n, outPath)
+	return nil
+}
+
+// RunEvalRegion fills the half-open world box with the formula src and writes the
+// touched non-empty chunks to out (directory or .voplpack, see vopl.World.Save).
+func RunEvalRegion(src, out string, box vopl.Box) error {
+	expr, err := vopl.ParseExpr(src)
+	if err != nil {
+		return err
+	}
+	world := vopl.NewWorld()
+	n, err := expr.FillRegion(world, box)
+	if err != nil {
+		return err
+	}
+	if err := world.Save(out); err != nil {
+		return err
+	}
+	fmt.Printf("eval: %d voxels in %d chunks written to %s\n", n, world.Len(), out)
+	return nil
+}
+
+// ParseRegion parses a world box from two corners "x0,y0,z0" and "x1,y1,z1".
+// Both corners are inclusive; the result is the half-open box covering them.
+func ParseRegion(from, to string) (vopl.Box, error) {
+	a, err := parseInt3(from)
+	if err != nil {
+		return vopl.Box{}, err
+	}
+	b, err := parseInt3(to)
+	if err != nil {
+		return vopl.Box{}, err
+	}
+	var box vopl.Box
+	for i := range 3 {
+		box.Min[i] = min(a[i], b[i])
+		box.Max[i] = max(a[i], b[i]) + 1
+	}
+	return box, nil
+}
+
+// parseInt3 parses "x,y,z".
+func parseInt3(s string) ([3]int, error) {
+	var v [3]int
+	parts := strings.Split(s, ",")
+	if len(parts) != 3 {
+		return v, fmt.Errorf("expected x,y,z: %q", s)
+	}
+	for i, p := range parts {
+		if _, err := fmt.Sscan(p, &v[i]); err != nil {
+			return v, fmt.Errorf("invalid coordinate %q: %w", s, err)
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+// FindMatch is one template occurrence reported by the find command. Offset is local
+// to the chunk Name; World is the same cell in world coordinates when the chunks are
+// named by chunk id.
+type FindMatch struct {
+	Name string `json:"name"`
+	vopl.TemplateMatch
+	World *[3]int `json:"world,omitempty"`
+}
+
+// LoadTemplate loads a search template: a .voplfab prefab as is, or a .vopl chunk
+// cropped to the bounds of its voxels.
+func LoadTemplate(path string) (*vopl.Clipboard, error) {
+	if strings.EqualFold(filepath.Ext(path), ".voplfab") {
+		return vopl.LoadPrefab(path)
+	}
+	grid, err := vopl.LoadVoplGrid(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load template: %w", err)
+	}
+	b, ok := grid.Bounds()
+	if !ok {
+		return nil, fmt.Errorf("template %s is empty", path)
+	}
+	return vopl.CopyRegion(grid, b)
+}
+
+// ParseTemplateOptions parses optional arguments for the find command:
+//
+//	rotate          also match the 24 rotations
+//	mirror          also match mirror images
+//	air=any|empty   empty template cells match anything (default) or only empty cells
+//	wildcard=<n>    palette index that matches any cell
+func ParseTemplateOptions(args []string) (vopl.TemplateOptions, error) {
+	opts := vopl.TemplateOptions{AirIsWildcard: true}
+	for _, arg := range args {
+		key, val, _ := strings.Cut(arg, "=")
+		switch key {
+		case "rotate":
+			opts.Rotations = true
+		case "mirror":
+			opts.Mirrors = true
+		case "air":
+			switch val {
+			case "any":
+				opts.AirIsWildcard = true
+			case "empty":
+				opts.AirIsWildcard = false
+			default:
+				return opts, fmt.Errorf("air must be any or empty: %q", val)
+			}
+		case "wildcard":
+			var c int
+			if _, err := fmt.Sscan(val, &c); err != nil || c < 1 || c > 255 {
+				return opts, fmt.Errorf("wildcard must be a palette index 1..255: %q", val)
+			}
+			opts.Wildcard = uint8(c)
+		default:
+			return opts, fmt.Errorf("unknown find option: %q", arg)
+		}
+	}
+	return opts, nil
+}
+
+// FindTemplate searches the chunks at path (see LoadGridSource) for the template. When
+// every chunk is named "<chunkId>.vopl" they are searched as one world (see
+// vopl.MatchTemplateWorld), so occurrences crossing chunk borders are found and each is
+// reported against the chunk holding its minimum corner. Otherwise each chunk is
+// searched on its own.
+func FindTemplate(tmpl *vopl.Clipboard, path string, opts vopl.TemplateOptions) ([]FindMatch, error) {
+	grids, err := LoadGridSource(path)
+	if err != nil {
+		return nil, err
+	}
+	out := []FindMatch{}
+	if world, names, ok := chunkWorld(grids); ok {
+		for _, m := range vopl.MatchTemplateWorld(world, tmpl, opts) {
+			at := m.Offset
+			c, local := vopl.ChunkOf(at[0], at[1], at[2])
+			name, ok := names[c]
+			if !ok {
+				name = c.FileName()
+			}
+			m.Offset = local
+			out = append(out, FindMatch{Name: name, TemplateMatch: m, World: &at})
+		}
+		return out, nil
+	}
+	for _, ng := range grids {
+		for _, m := range vopl.MatchTemplate(ng.Grid, tmpl, opts) {
+			out = append(out, FindMatch{Name: ng.Name, TemplateMatch: m})
+		}
+	}
+	return out, nil
+}
+
+// chunkWorld places grids in a world by the chunk id in their names, and returns the
+// name of each chunk. ok is false if any name is not a chunk file name.
+func chunkWorld(grids []NamedGrid) (*vopl.World, map[vopl.ChunkCoord]string, bool) {
+	world := vopl.NewWorld()
+	names := make(map[vopl.ChunkCoord]string, len(grids))
+	for _, ng := range grids {
+		c, err := vopl.ParseChunkFileName(ng.Name)
+		if err != nil || world.SetChunk(c, ng.Grid) != nil {
+			return nil, nil, false
+		}
+		names[c] = ng.Name
+	}
+	return world, names, len(grids) > 0
+}
+
+// RunFind writes the matches of the template at templatePath in the chunks at path to
+// w as a JSON array.
+func RunFind(templatePath, path string, opts vopl.TemplateOptions, w io.Writer) error {
+	tmpl, err := LoadTemplate(templatePath)
+	if err != nil {
+		return err
+	}
+	matches, err := FindTemplate(tmpl, path, opts)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(matches)
+}
@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+// RunHollowVOPL removes interior voxels deeper than thickness from inPath and writes outPath.
+func RunHollowVOPL(inPath, outPath string, thickness int) error {
+	grid, err := vopl.LoadVoplGrid(inPath)
+	if err != nil {
+		return fmt.Errorf("failed to load input VOPL: %w", err)
+	}
+	before := len(vopl.SaveVoplGridToBytes(grid))
+	removed := grid.Hollow(thickness)
+	if err := vopl.SaveVoplGrid(grid, outPath); err != nil {
+		return fmt.Errorf("failed to save VOPL: %w", err)
+	}
+	if fi, err := os.Stat(outPath); err == nil {
+		fmt.Printf("hollow: removed %d voxels (%d -> %d bytes)\n", removed, before, fi.Size())
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+// lodEntryName names LOD level files: "12.vopl" level 2 -> "12.lod2.vopl".
+func lodEntryName(base string, level int) string {
+	return fmt.Sprintf("%s.lod%d.vopl", strings.TrimSuffix(base, filepath.Ext(base)), level)
+}
+
+// RunLOD builds LOD levels 0..vopl.MaxLODLevel for inPath. If out ends in .voplpack the
+// levels are written as entries of a single pack; otherwise out is a directory that
+// receives one .vopl per level. Level N keeps its (16>>N)³ cells in the low corner of
+// the grid; mesh it with vopl.GenerateMeshLOD(grid, N).
+func RunLOD(inPath, out string, mode vopl.LODMode) error {
+	grid, err := vopl.LoadVoplGrid(inPath)
+	if err != nil {
+		return fmt.Errorf("failed to load input VOPL: %w", err)
+	}
+	levels := vopl.BuildLODs(grid, mode)
+	base := filepath.Base(inPath)
+
+	if strings.EqualFold(filepath.Ext(out), ".voplpack") {
+		grids := make([]NamedGrid, len(levels))
+		for i := range levels {
+			grids[i] = NamedGrid{Name: lodEntryName(base, levels[i].Level), Grid: &levels[i].Grid}
+		}
+		data, err := PackGrids(grids, vopl.PackCompZlib)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(out, data, 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("lod: %d levels packed into %s\n", len(levels), out)
+		return nil
+	}
+
+	if err := os.MkdirAll(out, 0o755); err != nil {
+		return err
+	}
+	for _, l := range levels {
+		path := filepath.Join(out, lodEntryName(base, l.Level))
+		if err := vopl.SaveVoplGrid(&l.Grid, path); err != nil {
+			return fmt.Errorf("failed to save %s: %w", path, err)
+		}
+		fmt.Printf("lod%d: %d³ cells, %d voxels\n", l.Level, l.Size, l.Grid.CountNonZero())
+	}
+	return nil
+}
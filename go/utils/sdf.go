@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+// RunSDF computes a signed distance field for inPath and writes it to outPath.
+// A .ktx extension produces a KTX 1.1 R32F 3D texture; anything else is raw
+// little-endian float32 (x fastest, then y, then z).
+func RunSDF(inPath, outPath string, supersample int) error {
+	grid, err := vopl.LoadVoplGrid(inPath)
+	if err != nil {
+		return fmt.Errorf("failed to load input VOPL: %w", err)
+	}
+	sdf, err := vopl.ComputeSDF(grid, supersample)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	if strings.EqualFold(filepath.Ext(outPath), ".ktx") {
+		err = sdf.WriteKTX(w)
+	} else {
+		err = sdf.WriteRaw(w)
+	}
+	if err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("sdf: %d³ samples written to %s\n", sdf.Size, outPath)
+	return f.Close()
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+// SimilarCluster is a group of near-duplicate chunks reported by the similar command.
+type SimilarCluster struct {
+	Members       []string `json:"members"`
+	MinSimilarity float64  `json:"minSimilarity"`
+}
+
+// FindSimilar clusters the chunks at path (see LoadGridSource) whose similarity is at
+// least threshold (see vopl.ClusterBySimilarity).
+func FindSimilar(path string, threshold float64) ([]SimilarCluster, error) {
+	if threshold <= 0 || threshold > 1 {
+		return nil, fmt.Errorf("threshold must be in (0,1]: %v", threshold)
+	}
+	named, err := LoadGridSource(path)
+	if err != nil {
+		return nil, err
+	}
+	grids := make([]*vopl.VoxelGrid, len(named))
+	for i, ng := range named {
+		grids[i] = ng.Grid
+	}
+	out := []SimilarCluster{}
+	for _, c := range vopl.ClusterBySimilarity(grids, threshold) {
+		sc := SimilarCluster{MinSimilarity: c.MinSimilarity}
+		for _, m := range c.Members {
+			sc.Members = append(sc.Members, named[m].Name)
+		}
+		out = append(out, sc)
+	}
+	return out, nil
+}
+
+// RunSimilar writes the clusters of near-duplicate chunks at path to w as a JSON array.
+func RunSimilar(path string, threshold float64, w io.Writer) error {
+	clusters, err := FindSimilar(path, threshold)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(clusters)
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+// NamedGrid is a decoded chunk together with its file or pack entry name.
+type NamedGrid struct {
+	Name string
+	Grid *vopl.VoxelGrid
+}
+
+// LoadGridSource decodes every chunk found at path, which may be a single .vopl file,
+// a .voplpack, or a directory of .vopl files (non-recursive). Results are sorted by name
+// for directories and keep entry order for packs.
+func LoadGridSource(path string) ([]NamedGrid, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return loadGridDir(path)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".voplpack") {
+		return loadGridPack(path)
+	}
+	grid, err := vopl.LoadVoplGrid(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return []NamedGrid{{Name: filepath.Base(path), Grid: grid}}, nil
+}
+
+func loadGridDir(dir string) ([]NamedGrid, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var out []NamedGrid
+	for _, e := range entries {
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(e.Name()), ".vopl") {
+			continue
+		}
+		grid, err := vopl.LoadVoplGrid(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name(), err)
+		}
+		out = append(out, NamedGrid{Name: e.Name(), Grid: grid})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func loadGridPack(path string) ([]NamedGrid, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pack, _, err := vopl.UnmarshalPack(data)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]NamedGrid, len(pack.Entries))
+	for i, e := range pack.Entries {
+		grid, err := vopl.LoadVoplGridFromBytes(vopl.BuildVOPLFromHeaderAndPayload(pack.Header, e.Enc, e.Payload))
+		if err != nil {
+			return nil, fmt.Errorf("entry %d (%s): %w", i, e.Name, err)
+		}
+		out[i] = NamedGrid{Name: e.Name, Grid: grid}
+	}
+	return out, nil
+}
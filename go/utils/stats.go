@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+// ChunkStats is the per-chunk record emitted by the stats command.
+type ChunkStats struct {
+	Name string `json:"name"`
+	vopl.GridStats
+	// Mass uses unit density for every palette index.
+	Mass vopl.MassProps `json:"mass"`
+}
+
+// CollectStats computes vopl.Stats for every chunk found at path (see LoadGridSource).
+func CollectStats(path string) ([]ChunkStats, error) {
+	grids, err := LoadGridSource(path)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ChunkStats, len(grids))
+	for i, ng := range grids {
+		out[i] = ChunkStats{Name: ng.Name, GridStats: vopl.Stats(ng.Grid), Mass: vopl.MassProperties(ng.Grid, nil)}
+	}
+	return out, nil
+}
+
+// RunStats writes statistics for the chunks at path to w as "json" or "csv".
+func RunStats(path, format string, w io.Writer) error {
+	stats, err := CollectStats(path)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case "", "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stats)
+	case "csv":
+		return writeStatsCSV(w, stats)
+	}
+	return fmt.Errorf("unknown stats format: %q (use json or csv)", format)
+}
+
+func writeStatsCSV(w io.Writer, stats []ChunkStats) error {
+	cw := csv.NewWriter(w)
+	header := []string{"name", "voxels", "minX", "minY", "minZ", "maxX", "maxY", "maxZ", "exposedFaces", "quads", "mass", "comX", "comY", "comZ", "histogram"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, s := range stats {
+		row := []string{
+			s.Name,
+			strconv.Itoa(s.VoxelCount),
+			strconv.Itoa(s.Bounds.Min[0]), strconv.Itoa(s.Bounds.Min[1]), strconv.Itoa(s.Bounds.Min[2]),
+			strconv.Itoa(s.Bounds.Max[0]), strconv.Itoa(s.Bounds.Max[1]), strconv.Itoa(s.Bounds.Max[2]),
+			strconv.Itoa(s.ExposedFaces),
+			strconv.Itoa(s.QuadCount),
+			formatFloat(s.Mass.Mass),
+			formatFloat(s.Mass.CenterOfMass[0]), formatFloat(s.Mass.CenterOfMass[1]), formatFloat(s.Mass.CenterOfMass[2]),
+			formatHistogram(s.Histogram),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatFloat(f float64) string { return strconv.FormatFloat(f, 'g', 6, 64) }
+
+// formatHistogram renders a histogram as "index:count" pairs separated by ';', sorted by index.
+func formatHistogram(h map[uint8]int) string {
+	keys := make([]int, 0, len(h))
+	for k := range h {
+		keys = append(keys, int(k))
+	}
+	sort.Ints(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%d:%d", k, h[uint8(k)])
+	}
+	return strings.Join(parts, ";")
+}
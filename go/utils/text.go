@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+// ParseTextOptions parses optional key=value arguments for the text command:
+//
+//	at=x,y,z        bottom-left-front cell of the text (default 0,0,0)
+//	plane=right,up  signed axes the text runs along (default x,y)
+//	depth=<n>       extrusion along right×up (default 1)
+//	color=<n>       palette index (default 1)
+//	scale=<n>       voxels per font pixel (default 1)
+//	wrap=<n>        maximum line width in voxels (default: no wrapping)
+func ParseTextOptions(args []string) ([3]int, vopl.TextOptions, error) {
+	var origin [3]int
+	opts := vopl.TextOptions{Color: 1}
+	for _, arg := range args {
+		key, val, ok := strings.Cut(arg, "=")
+		if !ok {
+			return origin, opts, fmt.Errorf("expected key=value, got %q", arg)
+		}
+		var err error
+		switch key {
+		case "at":
+			origin, err = parseInt3(val)
+		case "plane":
+			opts.Right, opts.Up, err = vopl.ParseTextPlane(val)
+		case "depth", "scale", "wrap":
+			var n int
+			if _, err = fmt.Sscan(val, &n); err == nil && n < 0 {
+				err = fmt.Errorf("must not be negative")
+			}
+			switch key {
+			case "depth":
+				opts.Depth = n
+			case "scale":
+				opts.Scale = n
+			default:
+				opts.Wrap = n
+			}
+		case "color":
+			var c int
+			if _, err = fmt.Sscan(val, &c); err == nil && (c < 0 || c > 255) {
+				err = fmt.Errorf("palette index out of range")
+			}
+			opts.Color = uint8(c)
+		default:
+			return origin, opts, fmt.Errorf("unknown text option: %q", key)
+		}
+		if err != nil {
+			return origin, opts, fmt.Errorf("invalid %s %q: %w", key, val, err)
+		}
+	}
+	return origin, opts, nil
+}
+
+// RunText renders text with the built-in bitmap font. A .vopl output receives a single
+// chunk in local coordinates (cells past its edges are dropped); any other output is
+// a directory or .voplpack of the touched chunks in world coordinates (see
+// vopl.World.Save).
+func RunText(text, out string, origin [3]int, opts vopl.TextOptions) error {
+	if strings.EqualFold(filepath.Ext(out), ".vopl") {
+		var grid vopl.VoxelGrid
+		n, err := vopl.RenderText(&grid, origin, text, opts)
+		if err != nil {
+			return err
+		}
+		if err := vopl.SaveVoplGrid(&grid, out); err != nil {
+			return fmt.Errorf("failed to save %s: %w", out, err)
+		}
+		fmt.Printf("text: %d voxels written to %s\n", n, out)
+		return nil
+	}
+	world := vopl.NewWorld()
+	n, err := vopl.RenderText(world, origin, text, opts)
+	if err != nil {
+		return err
+	}
+	if err := world.Save(out); err != nil {
+		return err
+	}
+	fmt.Printf("text: %d voxels in %d chunks written to %s\n", n, world.Len(), out)
+	return nil
+}
@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+// ApplyTransformOps applies a chain of textual transform ops to grid, left to right.
+// Supported ops:
+//
+//	rotx:N, roty:N, rotz:N   rotate N quarter turns about the axis (N may be negative; default 1)
+//	mirrorx, mirrory, mirrorz reflect across the plane perpendicular to the axis
+//	shift:dx,dy,dz[,wrap]    translate contents; voxels leaving the grid are clipped unless ",wrap"
+func ApplyTransformOps(grid *vopl.VoxelGrid, ops []string) (*vopl.VoxelGrid, error) {
+	out := grid
+	for _, op := range ops {
+		a, ok, err := parseAxisOp(op)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			if a.mirror {
+				out = out.Mirror(a.axis)
+			} else {
+				out = out.Rotate90(a.axis, a.turns)
+			}
+			continue
+		}
+		name, arg, _ := strings.Cut(strings.ToLower(strings.TrimSpace(op)), ":")
+		switch name {
+		case "shift":
+			parts := strings.Split(arg, ",")
+			if len(parts) != 3 && len(parts) != 4 {
+				return nil, fmt.Errorf("op %q: expected shift:dx,dy,dz[,wrap]", op)
+			}
+			var d [3]int
+			for i := range 3 {
+				if _, err := fmt.Sscan(parts[i], &d[i]); err != nil {
+					return nil, fmt.Errorf("op %q: invalid offset: %w", op, err)
+				}
+			}
+			mode := vopl.ShiftClip
+			if len(parts) == 4 {
+				switch parts[3] {
+				case "wrap":
+					mode = vopl.ShiftWrap
+				case "clip":
+				default:
+					return nil, fmt.Errorf("op %q: unknown shift mode %q", op, parts[3])
+				}
+			}
+			out = out.Shift(d[0], d[1], d[2], mode)
+		default:
+			return nil, fmt.Errorf("unknown transform op: %q", op)
+		}
+	}
+	return out, nil
+}
+
+// axisOp is a parsed rotation or mirror op.
+type axisOp struct {
+	mirror bool
+	axis   vopl.Axis
+	turns  int
+}
+
+// parseAxisOp parses the rotx|roty|rotz[:N] and mirrorx|mirrory|mirrorz ops shared by
+// ApplyTransformOps and ApplyClipboardOps. ok is false when op is neither.
+func parseAxisOp(op string) (a axisOp, ok bool, err error) {
+	name, arg, _ := strings.Cut(strings.ToLower(strings.TrimSpace(op)), ":")
+	var axis string
+	switch {
+	case strings.HasPrefix(name, "rot") && len(name) == 4:
+		axis, a.turns = name[3:], 1
+		if arg != "" {
+			if _, err := fmt.Sscan(arg, &a.turns); err != nil {
+				return a, true, fmt.Errorf("op %q: invalid turns: %w", op, err)
+			}
+		}
+	case strings.HasPrefix(name, "mirror") && len(name) == 7:
+		axis, a.mirror = name[6:], true
+	default:
+		return a, false, nil
+	}
+	if a.axis, err = vopl.ParseAxis(axis); err != nil {
+		return a, true, fmt.Errorf("op %q: %w", op, err)
+	}
+	return a, true, nil
+}
+
+// RunTransformVOPL loads inPath, applies ops (see ApplyTransformOps) and writes outPath.
+func RunTransformVOPL(inPath, outPath string, ops []string) error {
+	grid, err := vopl.LoadVoplGrid(inPath)
+	if err != nil {
+		return fmt.Errorf("failed to load input VOPL: %w", err)
+	}
+	out, err := ApplyTransformOps(grid, ops)
+	if err != nil {
+		return err
+	}
+	if err := vopl.SaveVoplGrid(out, outPath); err != nil {
+		return fmt.Errorf("failed to save VOPL: %w", err)
+	}
+	if fi, err := os.Stat(outPath); err == nil {
+		fmt.Printf(".vopl transformed (%d ops, %d bytes)\n", len(ops), fi.Size())
+	}
+	return nil
+}
@@ -1,36 +1,35 @@
 package utils
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/voxelsplace/vopl/go/vopl"
 )
 
-// updatesJSON is { "<chunkId>": { "<index>": <color>, ... }, ... }
-type updatesJSON map[string]map[string]int
-
-// indexToXYZ converts linear index (x + y*W + z*W*H) into coordinates.
-func indexToXYZ(index int) (x, y, z int) {
-	w, h := vopl.Width, vopl.Height
-	wh := w * h
-	z = index / wh
-	rem := index - z*wh
-	y = rem / w
-	x = rem - y*w
-	return
-}
-
-// RunUpdateVOPL applies a JSON updates blob to an existing .vopl file and writes a regenerated .vopl.
-// The JSON format is: { "<chunkId>": { "<index>": <color>, ... } }
-// Only a single chunk is supported here; extra chunks are ignored.
+// RunUpdateVOPL applies a JSON updates blob (see vopl.Updates) to inputPath and writes
+// the result to outputPath. A single .vopl file takes the document's only chunk, or the
+// chunk its "<chunkId>.vopl" name selects when the document covers several; a
+// directory or .voplpack takes every chunk (see RunUpdateWorld).
 func RunUpdateVOPL(jsonUpdates []byte, inputPath, outputPath string) error {
+	up, err := vopl.ParseUpdates(jsonUpdates)
+	if err != nil {
+		return err
+	}
+	if isWorldPath(inputPath) {
+		return RunUpdateWorld(up, inputPath, outputPath)
+	}
 	grid, err := vopl.LoadVoplGrid(inputPath)
 	if err != nil {
 		return fmt.Errorf("failed to load input VOPL: %w", err)
 	}
-	if err := applyJSONToGrid(grid, jsonUpdates); err != nil {
+	id, err := singleChunkID(up, inputPath)
+	if err != nil {
+		return err
+	}
+	if _, err := up.Apply(id, grid); err != nil {
 		return err
 	}
 	if err := vopl.SaveVoplGrid(grid, outputPath); err != nil {
@@ -44,10 +43,77 @@ func RunUpdateVOPL(jsonUpdates []byte, inputPath, outputPath string) error {
 	return nil
 }
 
-// RunJSONToVOPL applies JSON updates to an empty grid and saves it as a .vopl chunk file.
+// RunUpdateWorld applies every chunk of up to the world at inputPath (directory or
+// .voplpack of "<chunkId>.vopl" chunks, see vopl.LoadWorld), creating and dropping
+// chunks as needed, saves it to outputPath and prints one summary line per chunk.
+func RunUpdateWorld(up vopl.Updates, inputPath, outputPath string) error {
+	world, err := vopl.LoadWorld(inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to load input world: %w", err)
+	}
+	results, err := world.ApplyUpdates(up)
+	if err != nil {
+		return err
+	}
+	for _, r := range results {
+		state := ""
+		switch {
+		case r.Created:
+			state = " (created)"
+		case r.Removed:
+			state = " (removed, now empty)"
+		}
+		fmt.Printf("chunk %s (%d,%d,%d): %d cells written, %d voxels%s\n", r.ID, r.Coord[0], r.Coord[1], r.Coord[2], r.Written, r.Voxels, state)
+	}
+	if err := world.Save(outputPath); err != nil {
+		return fmt.Errorf("failed to save world: %w", err)
+	}
+	fmt.Printf("%d chunks updated, %d chunks saved in %s\n", len(results), world.Len(), outputPath)
+	return nil
+}
+
+// isWorldPath reports whether path names a directory or a .voplpack.
+func isWorldPath(path string) bool {
+	if strings.EqualFold(filepath.Ext(path), ".voplpack") {
+		return true
+	}
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
+
+// singleChunkID picks the chunk of up that applies to the single .vopl file at path:
+// the only chunk, or the one named by a "<chunkId>.vopl" file name.
+func singleChunkID(up vopl.Updates, path string) (string, error) {
+	if len(up) <= 1 {
+		for id := range up {
+			return id, nil
+		}
+		return "", nil
+	}
+	if c, err := vopl.ParseChunkFileName(path); err == nil {
+		for id := range up {
+			if got, err := vopl.ParseChunkID(id); err == nil && got == c {
+				fmt.Printf("applying chunk %s, ignoring %d other chunks\n", id, len(up)-1)
+				return id, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("updates cover %d chunks and %s is not named after one of them; use a directory or .voplpack to apply them all", len(up), filepath.Base(path))
+}
+
+// RunJSONToVOPL applies JSON updates to an empty grid and saves it as a .vopl chunk
+// file; the chunk is chosen as in RunUpdateVOPL, using outPath's name.
 func RunJSONToVOPL(jsonUpdates []byte, outPath string) error {
+	up, err := vopl.ParseUpdates(jsonUpdates)
+	if err != nil {
+		return err
+	}
+	id, err := singleChunkID(up, outPath)
+	if err != nil {
+		return err
+	}
 	var grid vopl.VoxelGrid
-	if err := applyJSONToGrid(&grid, jsonUpdates); err != nil {
+	if _, err := up.Apply(id, &grid); err != nil {
 		return err
 	}
 	if err := vopl.SaveVoplGrid(&grid, outPath); err != nil {
@@ -69,34 +135,3 @@ func RunJSONToVOPLFile(inJSONPath, outPath string) error {
 	}
 	return RunJSONToVOPL(data, outPath)
 }
-
-func applyJSONToGrid(grid *vopl.VoxelGrid, jsonBlob []byte) error {
-	var up updatesJSON
-	if err := json.Unmarshal(jsonBlob, &up); err != nil {
-		return fmt.Errorf("invalid updates JSON: %w", err)
-	}
-	// Apply all entries for the first chunk we see. Additional chunks are ignored for this single-grid tool.
-	for _, indices := range up {
-		for idxStr, col := range indices {
-			// parse idx
-			var idx int
-			if _, err := fmt.Sscan(idxStr, &idx); err != nil {
-				return fmt.Errorf("invalid voxel index '%s': %w", idxStr, err)
-			}
-			if idx < 0 || idx >= vopl.Width*vopl.Height*vopl.Depth {
-				// ignore out of bounds
-				continue
-			}
-			if col < 0 {
-				col = 0
-			}
-			if col > 255 {
-				col = 255
-			}
-			x, y, z := indexToXYZ(idx)
-			grid[y][x][z] = uint8(col)
-		}
-		break // only one chunk supported here
-	}
-	return nil
-}
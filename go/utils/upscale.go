@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+// RunUpscale enlarges inPath by factor and writes the resulting chunks to out
+// (directory or .voplpack, see vopl.World.Save), starting at chunk coordinate origin.
+func RunUpscale(inPath string, factor int, out string, origin vopl.ChunkCoord) error {
+	grid, err := vopl.LoadVoplGrid(inPath)
+	if err != nil {
+		return fmt.Errorf("failed to load input VOPL: %w", err)
+	}
+	chunks, err := vopl.Upscale(grid, factor, origin)
+	if err != nil {
+		return err
+	}
+	world := vopl.NewWorld()
+	for c, g := range chunks {
+		if err := world.SetChunk(c, g); err != nil {
+			return err
+		}
+	}
+	if err := world.Save(out); err != nil {
+		return err
+	}
+	fmt.Printf("upscale x%d: %d non-empty chunks written to %s\n", factor, world.Len(), out)
+	return nil
+}
+
+// ParseChunkCoord parses "cx,cy,cz".
+func ParseChunkCoord(s string) (vopl.ChunkCoord, error) {
+	var c vopl.ChunkCoord
+	parts := strings.Split(s, ",")
+	if len(parts) != 3 {
+		return c, fmt.Errorf("expected cx,cy,cz: %q", s)
+	}
+	for i, p := range parts {
+		if _, err := fmt.Sscan(p, &c[i]); err != nil {
+			return c, fmt.Errorf("invalid chunk coordinate %q: %w", s, err)
+		}
+	}
+	if !c.Valid() {
+		return c, fmt.Errorf("chunk coordinate out of range: %q", s)
+	}
+	return c, nil
+}
@@ -1,7 +1,9 @@
 package utils
 
 import (
+	"fmt"
 	"math"
+	"strings"
 
 	"github.com/voxelsplace/vopl/go/vopl"
 
@@ -9,28 +11,85 @@ import (
 	"github.com/qmuntal/gltf/modeler"
 )
 
+// ParseLightOptions parses the optional lighting arguments of vopl2glb. Any argument
+// enables lighting; start from full sky light and no emitters:
+//
+//	light                      use the defaults
+//	sky=<0..15>                sky light level
+//	emissive=i[:level],...     palette indices that emit light (level defaults to 15)
+//
+// It returns nil when args is empty.
+func ParseLightOptions(args []string) (*vopl.LightOptions, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	opts := vopl.DefaultLightOptions()
+	for _, arg := range args {
+		if arg == "light" {
+			continue
+		}
+		key, val, ok := strings.Cut(arg, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected light, sky=<n> or emissive=<list>, got %q", arg)
+		}
+		switch key {
+		case "sky":
+			var n int
+			if _, err := fmt.Sscan(val, &n); err != nil || n < 0 || n > vopl.MaxLight {
+				return nil, fmt.Errorf("sky must be 0..%d: %q", vopl.MaxLight, val)
+			}
+			opts.Sky = uint8(n)
+		case "emissive":
+			if opts.Emissive == nil {
+				opts.Emissive = map[uint8]uint8{}
+			}
+			for _, item := range strings.Split(val, ",") {
+				idxStr, levelStr, hasLevel := strings.Cut(item, ":")
+				var idx, level int
+				level = vopl.MaxLight
+				if _, err := fmt.Sscan(idxStr, &idx); err != nil || idx < 1 || idx > 255 {
+					return nil, fmt.Errorf("invalid emissive palette index: %q", item)
+				}
+				if hasLevel {
+					if _, err := fmt.Sscan(levelStr, &level); err != nil || level < 0 || level > vopl.MaxLight {
+						return nil, fmt.Errorf("emissive level must be 0..%d: %q", vopl.MaxLight, item)
+					}
+				}
+				opts.Emissive[uint8(idx)] = uint8(level)
+			}
+		default:
+			return nil, fmt.Errorf("unknown light option: %q", key)
+		}
+	}
+	return &opts, nil
+}
+
 func RunVOPL2GLB(inPath, outPath string) error {
+	return RunVOPL2GLBWithOptions(inPath, outPath, nil)
+}
+
+// RunVOPL2GLBWithOptions converts inPath to a .glb. When light is non-nil the flood-fill
+// lighting computed with it is baked into the vertex colors.
+func RunVOPL2GLBWithOptions(inPath, outPath string, light *vopl.LightOptions) error {
 	grid, err := vopl.LoadVoplGrid(inPath)
 	if err != nil {
 		return err
 	}
 
-	mesh := vopl.GenerateMesh(grid)
+	var mesh *vopl.Mesh
+	if light != nil {
+		mesh = vopl.GenerateLitMesh(grid, vopl.ComputeLight(grid, *light))
+	} else {
+		mesh = vopl.GenerateMesh(grid)
+	}
 
 	positions := make([][3]float32, len(mesh.Vertices))
-	colors := make([][4]float32, len(mesh.Vertices))
-	hasAlpha := false
-
 	for i, v := range mesh.Vertices {
 		positions[i] = v.Position
-		rgba, err := vopl.ParseHexColor(vopl.Palette[v.Color])
-		if err != nil {
-			return err
-		}
-		colors[i] = rgba
-		if rgba[3] < 1.0 {
-			hasAlpha = true
-		}
+	}
+	colors, hasAlpha, err := mesh.VertexColors()
+	if err != nil {
+		return err
 	}
 
 	indices := make([]uint32, len(mesh.Indices))
@@ -81,6 +81,22 @@ func CreatePack(inputFiles []string, outputFile string) error {
 	return os.WriteFile(outputFile, data, 0o644)
 }
 
+// PackGrids encodes in-memory grids (fixed BPP=6) and returns them as a .voplpack
+// using the raw layout with the given compression.
+func PackGrids(grids []NamedGrid, comp vopl.PackCompression) ([]byte, error) {
+	pack := &vopl.Pack{Header: vopl.VOPLHeader{Ver: 3, BPP: 6, W: vopl.Width, H: vopl.Height, D: vopl.Depth, Pal: 64}}
+	pack.Entries = make([]vopl.PackEntry, len(grids))
+	for i, ng := range grids {
+		b := vopl.SaveVoplGridToBytes(ng.Grid)
+		_, payload, err := vopl.ParseVOPLHeaderFromBytes(b)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ng.Name, err)
+		}
+		pack.Entries[i] = vopl.PackEntry{Name: ng.Name, Enc: b[5], Payload: payload}
+	}
+	return pack.Marshal(comp)
+}
+
 // UnpackToDir writes .vopl files from a .voplpack into outputDir.
 func UnpackToDir(packFile, outputDir string) error {
 	data, err := os.ReadFile(packFile)
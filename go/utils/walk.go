@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/voxelsplace/vopl/go/vopl"
+)
+
+// PathOptions configures the path command.
+type PathOptions struct {
+	Walk vopl.WalkOptions
+	// Margin grows the box spanned by start and goal on every side to form the search region.
+	Margin int
+}
+
+// ParseWalkOptions parses optional arguments for the path and navmesh commands on top
+// of vopl.DefaultWalkOptions:
+//
+//	headroom=<n>  empty cells needed above the feet
+//	step=<n>      highest ledge climbed in one move
+//	drop=<n>      deepest fall in one move
+func ParseWalkOptions(args []string) (vopl.WalkOptions, error) {
+	opts := vopl.DefaultWalkOptions()
+	for _, arg := range args {
+		key, val, _ := strings.Cut(arg, "=")
+		var dst *int
+		switch key {
+		case "headroom":
+			dst = &opts.Headroom
+		case "step":
+			dst = &opts.StepUp
+		case "drop":
+			dst = &opts.Drop
+		default:
+			return opts, fmt.Errorf("unknown walk option: %q", arg)
+		}
+		if _, err := fmt.Sscan(val, dst); err != nil || *dst < 0 {
+			return opts, fmt.Errorf("%s must be a non-negative integer: %q", key, val)
+		}
+	}
+	return opts, nil
+}
+
+// ParsePathOptions parses the walk options (see ParseWalkOptions) plus margin=<n>
+// (default 16).
+func ParsePathOptions(args []string) (PathOptions, error) {
+	opts := PathOptions{Margin: 16}
+	var walk []string
+	for _, arg := range args {
+		val, ok := strings.CutPrefix(arg, "margin=")
+		if !ok {
+			walk = append(walk, arg)
+			continue
+		}
+		if _, err := fmt.Sscan(val, &opts.Margin); err != nil || opts.Margin < 0 {
+			return opts, fmt.Errorf("margin must be a non-negative integer: %q", val)
+		}
+	}
+	var err error
+	opts.Walk, err = ParseWalkOptions(walk)
+	return opts, err
+}
+
+// LoadVoxels opens path for point lookups: a single .vopl file in local chunk
+// coordinates, or a directory or .voplpack of chunk files in world coordinates (see
+// vopl.LoadWorld).
+func LoadVoxels(path string) (vopl.Voxels, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() && strings.EqualFold(filepath.Ext(path), ".vopl") {
+		return vopl.LoadVoplGrid(path)
+	}
+	return vopl.LoadWorld(path)
+}
+
+// RunPath finds a walking path from start to goal in the world at path and writes its
+// feet cells to w as a JSON array of [x,y,z].
+func RunPath(path string, start, goal [3]int, opts PathOptions, w io.Writer) error {
+	world, err := LoadVoxels(path)
+	if err != nil {
+		return err
+	}
+	var box vopl.Box
+	for i := range 3 {
+		box.Min[i] = min(start[i], goal[i]) - opts.Margin
+		box.Max[i] = max(start[i], goal[i]) + opts.Margin + 1
+	}
+	cells, ok, err := vopl.FindPath(world, box, start, goal, opts.Walk)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("no path from %v to %v within margin %d", start, goal, opts.Margin)
+	}
+	enc := json.NewEncoder(w)
+	return enc.Encode(cells)
+}
+
+// RunNavmesh writes the navigation regions (see vopl.BuildNavRegions) of the world box
+// in the world at path to w as a JSON array.
+func RunNavmesh(path string, box vopl.Box, opts vopl.WalkOptions, w io.Writer) error {
+	world, err := LoadVoxels(path)
+	if err != nil {
+		return err
+	}
+	regions, err := vopl.BuildNavRegions(world, box, opts)
+	if err != nil {
+		return err
+	}
+	if regions == nil {
+		regions = []vopl.NavRegion{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(regions)
+}
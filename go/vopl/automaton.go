@@ -0,0 +1,263 @@
+package vopl
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// CellWall is returned by Cell.Get for positions outside the simulated region.
+// It is never written to a grid; rules should treat it as an immovable solid.
+const CellWall uint8 = 0xFF
+
+// Rule updates one cell of a given palette index during an Automaton step.
+// It inspects and changes the world through c.
+type Rule func(c *Cell)
+
+// Cell is the view a Rule gets of the cell being updated. Offsets are relative
+// to the cell's world position.
+type Cell struct {
+	X, Y, Z int
+	Color   uint8
+	a       *Automaton
+	rng     *rand.Rand
+}
+
+// Get returns the color at offset (dx,dy,dz), or CellWall outside the simulated region.
+func (c *Cell) Get(dx, dy, dz int) uint8 {
+	return c.a.get(c.X+dx, c.Y+dy, c.Z+dz)
+}
+
+// Set writes color at offset (dx,dy,dz). The target is locked for the rest of the step.
+func (c *Cell) Set(dx, dy, dz int, color uint8) bool {
+	return c.a.set(c.X+dx, c.Y+dy, c.Z+dz, color)
+}
+
+// Swap exchanges this cell with the one at offset (dx,dy,dz) and moves the cell there.
+// It fails (returning false) if the target is outside the region or already updated
+// this step.
+func (c *Cell) Swap(dx, dy, dz int) bool {
+	tx, ty, tz := c.X+dx, c.Y+dy, c.Z+dz
+	other := c.a.get(tx, ty, tz)
+	if other == CellWall || c.a.locked[[3]int{tx, ty, tz}] {
+		return false
+	}
+	c.a.set(tx, ty, tz, c.Color)
+	c.a.set(c.X, c.Y, c.Z, other)
+	c.X, c.Y, c.Z = tx, ty, tz
+	return true
+}
+
+// Rand returns the step's deterministic random source.
+func (c *Cell) Rand() *rand.Rand { return c.rng }
+
+// WorldChange is a cell whose color changed during a step, in world coordinates.
+type WorldChange struct {
+	Pos      [3]int
+	From, To uint8
+}
+
+// Automaton runs rule-driven cellular automaton steps over a ChunkMap.
+// Each step visits every occupied cell whose color has a rule, ordered by world y
+// (bottom first), then x, then z. A cell written during a step is not visited again
+// in that step, so particles move at most once per step. Randomness comes from a
+// source seeded with Seed and the step number, so runs are reproducible.
+type Automaton struct {
+	World ChunkMap
+	Rules map[uint8]Rule
+	Seed  int64
+	// Limit, when non-nil, restricts the simulation to a world box; cells outside read
+	// as CellWall. Without a limit particles may move into (and create) any valid chunk.
+	Limit *Box
+
+	steps   int64
+	locked  map[[3]int]bool
+	changes map[[3]int]*WorldChange
+}
+
+// NewGridAutomaton simulates a single grid as chunk (0,0,0) confined to its bounds.
+func NewGridAutomaton(g *VoxelGrid, rules map[uint8]Rule, seed int64) *Automaton {
+	return &Automaton{
+		World: ChunkMap{{}: g},
+		Rules: rules,
+		Seed:  seed,
+		Limit: &Box{Max: [3]int{Width, Height, Depth}},
+	}
+}
+
+func (a *Automaton) inLimit(x, y, z int) bool {
+	if a.Limit == nil {
+		c, _ := ChunkOf(x, y, z)
+		return c.Valid()
+	}
+	p := [3]int{x, y, z}
+	for i := range 3 {
+		if p[i] < a.Limit.Min[i] || p[i] >= a.Limit.Max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *Automaton) get(x, y, z int) uint8 {
+	if !a.inLimit(x, y, z) {
+		return CellWall
+	}
+	return a.World.Get(x, y, z)
+}
+
+func (a *Automaton) set(x, y, z int, color uint8) bool {
+	if color == CellWall || !a.inLimit(x, y, z) {
+		return false
+	}
+	p := [3]int{x, y, z}
+	old := a.World.Get(x, y, z)
+	if !a.World.Set(x, y, z, color) {
+		return false
+	}
+	a.locked[p] = true
+	if ch, ok := a.changes[p]; ok {
+		ch.To = color
+	} else if old != color {
+		a.changes[p] = &WorldChange{Pos: p, From: old, To: color}
+	}
+	return true
+}
+
+// Step advances the simulation once and returns the cells whose color differs from
+// the start of the step, sorted by world z, then y, then x.
+func (a *Automaton) Step() []WorldChange {
+	a.steps++
+	rng := rand.New(rand.NewSource(a.Seed ^ a.steps*0x5851F42D4C957F2D))
+	a.locked = map[[3]int]bool{}
+	a.changes = map[[3]int]*WorldChange{}
+
+	type active struct {
+		pos   [3]int
+		color uint8
+	}
+	var cells []active
+	for c, g := range a.World {
+		o := c.Origin()
+		for v := range g.Occupied() {
+			if a.Rules[v.Color] == nil {
+				continue
+			}
+			p := [3]int{o[0] + v.X, o[1] + v.Y, o[2] + v.Z}
+			if a.inLimit(p[0], p[1], p[2]) {
+				cells = append(cells, active{p, v.Color})
+			}
+		}
+	}
+	sort.Slice(cells, func(i, j int) bool {
+		pi, pj := cells[i].pos, cells[j].pos
+		if pi[1] != pj[1] {
+			return pi[1] < pj[1]
+		}
+		if pi[0] != pj[0] {
+			return pi[0] < pj[0]
+		}
+		return pi[2] < pj[2]
+	})
+	for _, ac := range cells {
+		p := ac.pos
+		if a.locked[p] || a.World.Get(p[0], p[1], p[2]) != ac.color {
+			continue
+		}
+		a.Rules[ac.color](&Cell{X: p[0], Y: p[1], Z: p[2], Color: ac.color, a: a, rng: rng})
+	}
+
+	out := make([]WorldChange, 0, len(a.changes))
+	for _, ch := range a.changes {
+		if ch.From != ch.To {
+			out = append(out, *ch)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		pi, pj := out[i].Pos, out[j].Pos
+		if pi[2] != pj[2] {
+			return pi[2] < pj[2]
+		}
+		if pi[1] != pj[1] {
+			return pi[1] < pj[1]
+		}
+		return pi[0] < pj[0]
+	})
+	a.pruneEmpty()
+	return out
+}
+
+// pruneEmpty drops chunks that became empty during the step.
+func (a *Automaton) pruneEmpty() {
+	for c, g := range a.World {
+		if g.IsEmpty() {
+			delete(a.World, c)
+		}
+	}
+}
+
+// ChangesToUpdates converts world changes into an updates JSON document keyed by chunk id.
+func ChangesToUpdates(changes []WorldChange) Updates {
+	u := Updates{}
+	for _, ch := range changes {
+		c, l := ChunkOf(ch.Pos[0], ch.Pos[1], ch.Pos[2])
+		u.Add(c.ID(), []VoxelChange{{X: l[0], Y: l[1], Z: l[2], From: ch.From, To: ch.To}})
+	}
+	return u
+}
+
+// FallingRule makes a cell fall like sand: straight down into empty space, otherwise
+// diagonally down in a random free direction.
+func FallingRule() Rule {
+	return func(c *Cell) {
+		if c.Get(0, -1, 0) == 0 && c.Swap(0, -1, 0) {
+			return
+		}
+		tryRandom(c, diagonalsDown[:])
+	}
+}
+
+// LiquidRule makes a cell fall like FallingRule and otherwise flow sideways into a
+// random empty face neighbour.
+func LiquidRule() Rule {
+	return func(c *Cell) {
+		if c.Get(0, -1, 0) == 0 && c.Swap(0, -1, 0) {
+			return
+		}
+		if tryRandom(c, diagonalsDown[:]) {
+			return
+		}
+		tryRandom(c, sideways[:])
+	}
+}
+
+// FireRule makes a cell burn: each step it ignites flammable face neighbours with
+// probability spread (turning them into the fire's color) and burns out to empty with
+// probability burnout.
+func FireRule(flammable map[uint8]bool, spread, burnout float64) Rule {
+	return func(c *Cell) {
+		for _, d := range faceOffsets {
+			if n := c.Get(d[0], d[1], d[2]); n != CellWall && flammable[n] && c.Rand().Float64() < spread {
+				c.Set(d[0], d[1], d[2], c.Color)
+			}
+		}
+		if c.Rand().Float64() < burnout {
+			c.Set(0, 0, 0, 0)
+		}
+	}
+}
+
+var diagonalsDown = [4][3]int{{1, -1, 0}, {-1, -1, 0}, {0, -1, 1}, {0, -1, -1}}
+
+var sideways = [4][3]int{{1, 0, 0}, {-1, 0, 0}, {0, 0, 1}, {0, 0, -1}}
+
+// tryRandom swaps c into the first empty offset of dirs, visited in random order.
+func tryRandom(c *Cell, dirs [][3]int) bool {
+	order := c.Rand().Perm(len(dirs))
+	for _, i := range order {
+		d := dirs[i]
+		if c.Get(d[0], d[1], d[2]) == 0 && c.Swap(d[0], d[1], d[2]) {
+			return true
+		}
+	}
+	return false
+}
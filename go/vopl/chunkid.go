@@ -0,0 +1,103 @@
+package vopl
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// MaxChunkCoord bounds chunk coordinates: each axis must lie in [0, MaxChunkCoord]
+// so it fits the 21 bits per axis of Morton3D64.
+const MaxChunkCoord = 1<<21 - 1
+
+// ChunkCoord is an integer chunk position as [x,y,z]. Chunk (cx,cy,cz) covers world
+// voxels [cx*Width, (cx+1)*Width) and likewise for y and z.
+type ChunkCoord [3]int
+
+// Valid reports whether every axis is within [0, MaxChunkCoord].
+func (c ChunkCoord) Valid() bool {
+	for _, v := range c {
+		if v < 0 || v > MaxChunkCoord {
+			return false
+		}
+	}
+	return true
+}
+
+// ID returns the chunk id used as the key of updates JSON documents and as the
+// .vopl file name: the decimal Morton3D64 code of the coordinates.
+func (c ChunkCoord) ID() string {
+	return strconv.FormatUint(Morton3D64(uint32(c[0]), uint32(c[1]), uint32(c[2])), 10)
+}
+
+// FileName returns "<id>.vopl".
+func (c ChunkCoord) FileName() string { return c.ID() + ".vopl" }
+
+// Origin returns the world coordinates of the chunk's cell (0,0,0).
+func (c ChunkCoord) Origin() [3]int {
+	return [3]int{c[0] * Width, c[1] * Height, c[2] * Depth}
+}
+
+// ParseChunkID decodes a chunk id (see ChunkCoord.ID).
+func ParseChunkID(id string) (ChunkCoord, error) {
+	code, err := strconv.ParseUint(id, 10, 64)
+	if err != nil || code >= 1<<63 {
+		return ChunkCoord{}, fmt.Errorf("invalid chunk id: %q", id)
+	}
+	x, y, z := MortonDecode3D64(code)
+	return ChunkCoord{int(x), int(y), int(z)}, nil
+}
+
+// ParseChunkFileName decodes a chunk file name of the form "<id>.vopl".
+func ParseChunkFileName(name string) (ChunkCoord, error) {
+	base := filepath.Base(name)
+	return ParseChunkID(strings.TrimSuffix(base, filepath.Ext(base)))
+}
+
+// ChunkOf splits a world coordinate into its chunk coordinate and the local cell inside it.
+func ChunkOf(wx, wy, wz int) (ChunkCoord, [3]int) {
+	c := ChunkCoord{floorDiv(wx, Width), floorDiv(wy, Height), floorDiv(wz, Depth)}
+	return c, [3]int{wx - c[0]*Width, wy - c[1]*Height, wz - c[2]*Depth}
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+// ChunkMap holds chunks keyed by chunk coordinate and addresses them in world coordinates.
+type ChunkMap map[ChunkCoord]*VoxelGrid
+
+// Get returns the palette index at world position (wx,wy,wz), or 0 for missing chunks.
+func (m ChunkMap) Get(wx, wy, wz int) uint8 {
+	c, l := ChunkOf(wx, wy, wz)
+	g := m[c]
+	if g == nil {
+		return 0
+	}
+	return g[l[1]][l[0]][l[2]]
+}
+
+// Set writes color at world position (wx,wy,wz), creating the chunk when a non-zero
+// color lands in a missing one. It reports false for positions whose chunk coordinate
+// is not Valid.
+func (m ChunkMap) Set(wx, wy, wz int, color uint8) bool {
+	c, l := ChunkOf(wx, wy, wz)
+	if !c.Valid() {
+		return false
+	}
+	g := m[c]
+	if g == nil {
+		if color == 0 {
+			return true
+		}
+		g = new(VoxelGrid)
+		m[c] = g
+	}
+	g[l[1]][l[0]][l[2]] = color
+	return true
+}
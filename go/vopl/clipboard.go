@@ -0,0 +1,251 @@
+package vopl
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Clipboard is a box of voxels copied out of a grid or a multi-chunk world, with an
+// anchor cell that lands on the target position when pasted. Prefabs are clipboards
+// saved to disk (see MarshalBinary).
+type Clipboard struct {
+	Size   [3]int
+	Anchor [3]int
+	// Data holds palette indices with x varying fastest, then y, then z.
+	Data []uint8
+}
+
+const (
+	// MaxClipboardSize bounds each clipboard axis so sizes fit the prefab header.
+	MaxClipboardSize = 1<<16 - 1
+	// MaxClipboardCells bounds the total volume of a clipboard.
+	MaxClipboardCells = 1 << 27
+)
+
+// NewClipboard returns an empty clipboard of the given size.
+func NewClipboard(size [3]int) (*Clipboard, error) {
+	for _, s := range size {
+		if s < 1 || s > MaxClipboardSize {
+			return nil, fmt.Errorf("clipboard size must be 1..%d per axis, got %v", MaxClipboardSize, size)
+		}
+	}
+	if size[0]*size[1]*size[2] > MaxClipboardCells {
+		return nil, fmt.Errorf("clipboard %v exceeds %d cells", size, MaxClipboardCells)
+	}
+	return &Clipboard{Size: size, Data: make([]uint8, size[0]*size[1]*size[2])}, nil
+}
+
+func (c *Clipboard) index(x, y, z int) (int, bool) {
+	if x < 0 || y < 0 || z < 0 || x >= c.Size[0] || y >= c.Size[1] || z >= c.Size[2] {
+		return 0, false
+	}
+	return x + c.Size[0]*(y+c.Size[1]*z), true
+}
+
+// Get returns the color at clipboard position (x,y,z), or 0 outside it.
+func (c *Clipboard) Get(x, y, z int) uint8 {
+	if i, ok := c.index(x, y, z); ok {
+		return c.Data[i]
+	}
+	return 0
+}
+
+// Set writes color at clipboard position (x,y,z) and reports whether it was inside.
+func (c *Clipboard) Set(x, y, z int, color uint8) bool {
+	i, ok := c.index(x, y, z)
+	if ok {
+		c.Data[i] = color
+	}
+	return ok
+}
+
+// CopyRegion copies the half-open box b out of src (a *VoxelGrid in local coordinates
+// or a ChunkMap in world coordinates). The anchor starts at the box's minimum corner.
+func CopyRegion(src Voxels, b Box) (*Clipboard, error) {
+	c, err := NewClipboard(b.Size())
+	if err != nil {
+		return nil, err
+	}
+	for z := range c.Size[2] {
+		for y := range c.Size[1] {
+			for x := range c.Size[0] {
+				c.Set(x, y, z, src.Get(b.Min[0]+x, b.Min[1]+y, b.Min[2]+z))
+			}
+		}
+	}
+	return c, nil
+}
+
+// Rotate90 returns a copy rotated by quarterTurns * 90° about axis, with the same
+// handedness as VoxelGrid.Rotate90. The anchor rotates with the contents.
+func (c *Clipboard) Rotate90(axis Axis, quarterTurns int) *Clipboard {
+	out := c
+	for range ((quarterTurns % 4) + 4) % 4 {
+		out = out.rotateOnce(axis)
+	}
+	if out == c {
+		out = c.clone()
+	}
+	return out
+}
+
+func (c *Clipboard) rotateOnce(axis Axis) *Clipboard {
+	s := c.Size
+	// rotate maps a clipboard position to its rotated position.
+	var rotate func(p [3]int) [3]int
+	var size [3]int
+	switch axis {
+	case AxisX:
+		size = [3]int{s[0], s[2], s[1]}
+		rotate = func(p [3]int) [3]int { return [3]int{p[0], s[2] - 1 - p[2], p[1]} }
+	case AxisY:
+		size = [3]int{s[2], s[1], s[0]}
+		rotate = func(p [3]int) [3]int { return [3]int{p[2], p[1], s[0] - 1 - p[0]} }
+	default:
+		size = [3]int{s[1], s[0], s[2]}
+		rotate = func(p [3]int) [3]int { return [3]int{s[1] - 1 - p[1], p[0], p[2]} }
+	}
+	return c.remap(size, rotate)
+}
+
+// Mirror returns a copy reflected across the plane perpendicular to axis through the
+// clipboard's center. The anchor is reflected too.
+func (c *Clipboard) Mirror(axis Axis) *Clipboard {
+	return c.remap(c.Size, func(p [3]int) [3]int {
+		p[axis] = c.Size[axis] - 1 - p[axis]
+		return p
+	})
+}
+
+func (c *Clipboard) remap(size [3]int, f func([3]int) [3]int) *Clipboard {
+	out := &Clipboard{Size: size, Anchor: f(c.Anchor), Data: make([]uint8, len(c.Data))}
+	for z := range c.Size[2] {
+		for y := range c.Size[1] {
+			for x := range c.Size[0] {
+				p := f([3]int{x, y, z})
+				out.Set(p[0], p[1], p[2], c.Get(x, y, z))
+			}
+		}
+	}
+	return out
+}
+
+func (c *Clipboard) clone() *Clipboard {
+	out := *c
+	out.Data = append([]uint8(nil), c.Data...)
+	return &out
+}
+
+// Bounds returns the destination box covered when pasting with the anchor at at.
+func (c *Clipboard) Bounds(at [3]int) Box {
+	var b Box
+	for i := range 3 {
+		b.Min[i] = at[i] - c.Anchor[i]
+		b.Max[i] = b.Min[i] + c.Size[i]
+	}
+	return b
+}
+
+// Paste writes the clipboard into dst with its anchor at at. With airTransparent,
+// empty clipboard cells leave the destination untouched; otherwise they clear it.
+// It returns the number of cells written.
+func (c *Clipboard) Paste(dst VoxelWriter, at [3]int, airTransparent bool) int {
+	b := c.Bounds(at)
+	n := 0
+	for z := range c.Size[2] {
+		for y := range c.Size[1] {
+			for x := range c.Size[0] {
+				color := c.Get(x, y, z)
+				if color == 0 && airTransparent {
+					continue
+				}
+				if dst.Set(b.Min[0]+x, b.Min[1]+y, b.Min[2]+z, color) {
+					n++
+				}
+			}
+		}
+	}
+	return n
+}
+
+// prefabMagic starts a prefab file: magic, version, then size and anchor as uint16
+// triples, then the zlib-compressed Data.
+const prefabMagic = "VOPF"
+
+// MarshalBinary encodes the clipboard as a prefab.
+func (c *Clipboard) MarshalBinary() ([]byte, error) {
+	for i := range 3 {
+		if c.Size[i] < 1 || c.Size[i] > MaxClipboardSize || c.Anchor[i] < 0 || c.Anchor[i] >= c.Size[i] {
+			return nil, fmt.Errorf("prefab anchor %v must lie inside size %v", c.Anchor, c.Size)
+		}
+	}
+	var buf bytes.Buffer
+	buf.WriteString(prefabMagic)
+	buf.WriteByte(1)
+	for _, v := range [6]int{c.Size[0], c.Size[1], c.Size[2], c.Anchor[0], c.Anchor[1], c.Anchor[2]} {
+		_ = binary.Write(&buf, binary.LittleEndian, uint16(v))
+	}
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write(c.Data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalClipboard decodes a prefab written by MarshalBinary.
+func UnmarshalClipboard(data []byte) (*Clipboard, error) {
+	if len(data) < 5+12 || string(data[:4]) != prefabMagic {
+		return nil, fmt.Errorf("não é um prefab VOPF válido")
+	}
+	if data[4] != 1 {
+		return nil, fmt.Errorf("prefab versão não suportada: %d", data[4])
+	}
+	var v [6]uint16
+	if err := binary.Read(bytes.NewReader(data[5:17]), binary.LittleEndian, &v); err != nil {
+		return nil, err
+	}
+	c, err := NewClipboard([3]int{int(v[0]), int(v[1]), int(v[2])})
+	if err != nil {
+		return nil, err
+	}
+	c.Anchor = [3]int{int(v[3]), int(v[4]), int(v[5])}
+	for i := range 3 {
+		if c.Anchor[i] >= c.Size[i] {
+			return nil, fmt.Errorf("prefab anchor %v outside size %v", c.Anchor, c.Size)
+		}
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(data[17:]))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	if _, err := io.ReadFull(zr, c.Data); err != nil {
+		return nil, fmt.Errorf("prefab data truncated: %w", err)
+	}
+	return c, nil
+}
+
+// SavePrefab writes c to path.
+func SavePrefab(c *Clipboard, path string) error {
+	data, err := c.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// LoadPrefab reads a prefab written by SavePrefab.
+func LoadPrefab(path string) (*Clipboard, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return UnmarshalClipboard(data)
+}
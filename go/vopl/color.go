@@ -31,3 +31,22 @@ func ParseHexColor(hex string) ([4]float32, error) {
 	}
 	return [4]float32{float32(r) / 255, float32(g) / 255, float32(b) / 255, float32(a) / 255}, nil
 }
+
+// NearestPaletteIndex returns the opaque palette index (1..63) closest to the given RGB
+// color by squared Euclidean distance. Ties resolve to the lowest index.
+func NearestPaletteIndex(r, g, b uint8) uint8 {
+	best, bestD := uint8(1), -1
+	for i := 1; i < len(Palette); i++ {
+		c, err := ParseHexColor(Palette[uint8(i)])
+		if err != nil {
+			continue
+		}
+		dr := int(c[0]*255+0.5) - int(r)
+		dg := int(c[1]*255+0.5) - int(g)
+		db := int(c[2]*255+0.5) - int(b)
+		if d := dr*dr + dg*dg + db*db; bestD < 0 || d < bestD {
+			best, bestD = uint8(i), d
+		}
+	}
+	return best
+}
@@ -0,0 +1,173 @@
+package vopl
+
+import "fmt"
+
+// Connectivity defines which neighbouring cells are considered connected:
+// 6 shares a face, 18 a face or edge, 26 a face, edge or corner.
+type Connectivity uint8
+
+const (
+	Conn6  Connectivity = 6
+	Conn18 Connectivity = 18
+	Conn26 Connectivity = 26
+)
+
+// ParseConnectivity accepts 6, 18 or 26.
+func ParseConnectivity(n int) (Connectivity, error) {
+	switch n {
+	case 6, 18, 26:
+		return Connectivity(n), nil
+	}
+	return 0, fmt.Errorf("connectivity must be 6, 18 or 26 (got %d)", n)
+}
+
+// offsets returns the neighbour deltas for the connectivity.
+func (c Connectivity) offsets() [][3]int {
+	maxManhattan := 1
+	switch c {
+	case Conn18:
+		maxManhattan = 2
+	case Conn26:
+		maxManhattan = 3
+	}
+	var out [][3]int
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			for dz := -1; dz <= 1; dz++ {
+				m := abs(dx) + abs(dy) + abs(dz)
+				if m == 0 || m > maxManhattan {
+					continue
+				}
+				out = append(out, [3]int{dx, dy, dz})
+			}
+		}
+	}
+	return out
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// Box is an integer axis-aligned box in grid cells, as [x,y,z] coordinates.
+// Min is inclusive and Max is exclusive, matching VoxelGrid.FillBox.
+type Box struct {
+	Min [3]int `json:"min"`
+	Max [3]int `json:"max"`
+}
+
+// Size returns the extent of the box along x, y and z.
+func (b Box) Size() [3]int {
+	return [3]int{b.Max[0] - b.Min[0], b.Max[1] - b.Min[1], b.Max[2] - b.Min[2]}
+}
+
+// extend grows b to include cell (x,y,z). An empty (zero) box must be seeded first.
+func (b *Box) extend(x, y, z int) {
+	p := [3]int{x, y, z}
+	for i := range 3 {
+		if p[i] < b.Min[i] {
+			b.Min[i] = p[i]
+		}
+		if p[i]+1 > b.Max[i] {
+			b.Max[i] = p[i] + 1
+		}
+	}
+}
+
+func cellBox(x, y, z int) Box {
+	return Box{Min: [3]int{x, y, z}, Max: [3]int{x + 1, y + 1, z + 1}}
+}
+
+// Component describes one connected group of occupied voxels.
+type Component struct {
+	// Label is the value stored for this component's cells in ComponentLabels (1-based).
+	Label int
+	// Size is the number of voxels.
+	Size int
+	// Bounds is the occupied bounding box.
+	Bounds Box
+	// Colors counts voxels per palette index.
+	Colors map[uint8]int
+	// Grounded is true when at least one voxel lies on the floor (y=0).
+	Grounded bool
+}
+
+// ComponentLabels maps each cell to its component label, indexed [y][x][z] like VoxelGrid.
+// Empty cells are 0.
+type ComponentLabels [Height][Width][Depth]uint16
+
+// LabelComponents labels the connected components of occupied voxels in g.
+// Components are numbered in discovery order, scanning y, then x, then z.
+func LabelComponents(g *VoxelGrid, conn Connectivity) (*ComponentLabels, []Component) {
+	labels := new(ComponentLabels)
+	offs := conn.offsets()
+	var comps []Component
+	queue := make([][3]int, 0, 64)
+	for y := range Height {
+		for x := range Width {
+			for z := range Depth {
+				if g[y][x][z] == 0 || labels[y][x][z] != 0 {
+					continue
+				}
+				label := uint16(len(comps) + 1)
+				comp := Component{Label: int(label), Bounds: cellBox(x, y, z), Colors: map[uint8]int{}}
+				labels[y][x][z] = label
+				queue = append(queue[:0], [3]int{x, y, z})
+				for len(queue) > 0 {
+					p := queue[len(queue)-1]
+					queue = queue[:len(queue)-1]
+					comp.Size++
+					comp.Colors[g[p[1]][p[0]][p[2]]]++
+					comp.Bounds.extend(p[0], p[1], p[2])
+					if p[1] == 0 {
+						comp.Grounded = true
+					}
+					for _, d := range offs {
+						nx, ny, nz := p[0]+d[0], p[1]+d[1], p[2]+d[2]
+						if !InBounds(nx, ny, nz) || g[ny][nx][nz] == 0 || labels[ny][nx][nz] != 0 {
+							continue
+						}
+						labels[ny][nx][nz] = label
+						queue = append(queue, [3]int{nx, ny, nz})
+					}
+				}
+				comps = append(comps, comp)
+			}
+		}
+	}
+	return labels, comps
+}
+
+// FloatingComponents returns the components with no voxel on the floor (y=0).
+func FloatingComponents(g *VoxelGrid, conn Connectivity) []Component {
+	_, comps := LabelComponents(g, conn)
+	var out []Component
+	for _, c := range comps {
+		if !c.Grounded {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// RemoveFloating clears every component not connected to the floor (y=0)
+// and returns the number of voxels removed.
+func (g *VoxelGrid) RemoveFloating(conn Connectivity) int {
+	labels, comps := LabelComponents(g, conn)
+	removed := 0
+	for y := range Height {
+		for x := range Width {
+			for z := range Depth {
+				l := labels[y][x][z]
+				if l != 0 && !comps[l-1].Grounded {
+					g[y][x][z] = 0
+					removed++
+				}
+			}
+		}
+	}
+	return removed
+}
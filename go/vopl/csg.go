@@ -0,0 +1,112 @@
+package vopl
+
+import "fmt"
+
+// CSGOp selects the boolean operation performed by CSG.
+type CSGOp uint8
+
+const (
+	CSGUnion     CSGOp = 0 // cells occupied in A or B
+	CSGIntersect CSGOp = 1 // cells occupied in both A and B
+	CSGSubtract  CSGOp = 2 // cells occupied in A but not in B
+	CSGXor       CSGOp = 3 // cells occupied in exactly one of A and B
+)
+
+// ParseCSGOp accepts "union", "intersect", "subtract" or "xor".
+func ParseCSGOp(s string) (CSGOp, error) {
+	switch s {
+	case "union":
+		return CSGUnion, nil
+	case "intersect":
+		return CSGIntersect, nil
+	case "subtract":
+		return CSGSubtract, nil
+	case "xor":
+		return CSGXor, nil
+	}
+	return 0, fmt.Errorf("unknown CSG operation: %q", s)
+}
+
+// OverlapPolicy decides the color of a cell occupied in both operands.
+type OverlapPolicy uint8
+
+const (
+	OverlapKeepA OverlapPolicy = 0 // keep A's color
+	OverlapKeepB OverlapPolicy = 1 // keep B's color
+	OverlapFixed OverlapPolicy = 2 // use CSGOptions.Color
+)
+
+// CSGOptions configures a CSG operation. The zero value places B at the origin
+// and keeps A's color on overlaps.
+type CSGOptions struct {
+	// Offset translates B into A's space: B[y][x][z] lands on A at (x+Offset[0], y+Offset[1], z+Offset[2]).
+	// Parts of B that fall outside the grid are ignored.
+	Offset [3]int
+	// Overlap resolves colors where both operands are occupied (union and intersect).
+	Overlap OverlapPolicy
+	// Color is the palette index used by OverlapFixed.
+	Color uint8
+}
+
+// CSG combines a and b with op and returns a new grid; neither operand is modified.
+func CSG(op CSGOp, a, b *VoxelGrid, opts CSGOptions) *VoxelGrid {
+	var out VoxelGrid
+	for y := range Height {
+		for x := range Width {
+			for z := range Depth {
+				ca := a[y][x][z]
+				cb := b.Get(x-opts.Offset[0], y-opts.Offset[1], z-opts.Offset[2])
+				var c uint8
+				switch op {
+				case CSGUnion:
+					switch {
+					case ca != 0 && cb != 0:
+						c = opts.resolve(ca, cb)
+					case ca != 0:
+						c = ca
+					default:
+						c = cb
+					}
+				case CSGIntersect:
+					if ca != 0 && cb != 0 {
+						c = opts.resolve(ca, cb)
+					}
+				case CSGSubtract:
+					if cb == 0 {
+						c = ca
+					}
+				case CSGXor:
+					if ca == 0 {
+						c = cb
+					} else if cb == 0 {
+						c = ca
+					}
+				}
+				out[y][x][z] = c
+			}
+		}
+	}
+	return &out
+}
+
+func (o CSGOptions) resolve(ca, cb uint8) uint8 {
+	switch o.Overlap {
+	case OverlapKeepB:
+		return cb
+	case OverlapFixed:
+		return o.Color
+	}
+	return ca
+}
+
+// Union is shorthand for CSG(CSGUnion, a, b, opts).
+func Union(a, b *VoxelGrid, opts CSGOptions) *VoxelGrid { return CSG(CSGUnion, a, b, opts) }
+
+// Intersect is shorthand for CSG(CSGIntersect, a, b, opts).
+func Intersect(a, b *VoxelGrid, opts CSGOptions) *VoxelGrid { return CSG(CSGIntersect, a, b, opts) }
+
+// Subtract is shorthand for CSG(CSGSubtract, a, b, opts).
+func Subtract(a, b *VoxelGrid, opts CSGOptions) *VoxelGrid { return CSG(CSGSubtract, a, b, opts) }
+
+// Xor is shorthand for CSG(CSGXor, a, b, opts).
+func Xor(a, b *VoxelGrid, opts CSGOptions) *VoxelGrid { return CSG(CSGXor, a, b, opts) }
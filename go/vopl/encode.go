@@ -3,7 +3,9 @@ package vopl
 import (
 	"bytes"
 	"compress/zlib"
+	"fmt"
 	"io"
+	"math/bits"
 )
 
 const (
@@ -49,23 +51,25 @@ func encodeSparse(grid *VoxelGrid, bpp uint8) []byte {
 }
 
 func encodeSparse2(grid *VoxelGrid, bpp uint8) []byte {
-	stream := flatten(grid)
-	// 4096-bit occupancy bitmap -> 512 bytes
+	// 4096-bit occupancy bitmap in Morton order -> 512 bytes, filled straight from the
+	// occupancy bitset; ranked holds the colors at their Morton rank.
 	bitmap := make([]byte, 512)
-	nonzeros := make([]uint8, 0, len(stream))
-	for i, v := range stream {
-		if v != 0 {
-			bitmap[i>>3] |= 1 << (uint(i) & 7)
-			nonzeros = append(nonzeros, v)
-		}
+	var ranked [Width * Height * Depth]uint8
+	occ := grid.Occupancy()
+	for c := range occ.Cells() {
+		rank := linearToMortonRank[c[0]+c[2]*Width+c[1]*Width*Depth]
+		bitmap[rank>>3] |= 1 << (rank & 7)
+		ranked[rank] = grid[c[1]][c[0]][c[2]]
 	}
-	if len(nonzeros) == 0 {
+	if occ.IsEmpty() {
 		// only bitmap, no values
-		return append([]byte{}, bitmap...)
+		return bitmap
 	}
 	bw := newBitWriter()
-	for _, c := range nonzeros {
-		bw.writeBits(uint64(c), bpp)
+	for i, b := range bitmap {
+		for ; b != 0; b &= b - 1 {
+			bw.writeBits(uint64(ranked[i<<3|bits.TrailingZeros8(b)]), bpp)
+		}
 	}
 	values := bw.bytes()
 	out := make([]byte, 0, 512+len(values))
@@ -91,6 +95,26 @@ func zlibDecompress(b []byte) ([]byte, error) {
 	return io.ReadAll(zr)
 }
 
+// encodePayload encodes grid with the given encoding byte (base encoding, plus 0x80
+// for zlib), reproducing what bestEncoding produced for that choice.
+func encodePayload(grid *VoxelGrid, bpp, enc uint8) ([]byte, error) {
+	var payload []byte
+	switch enc & 0x7F {
+	case encDense:
+		payload = encodeDense(grid, bpp)
+	case encSparse:
+		payload = encodeSparse(grid, bpp)
+	case encSparse2:
+		payload = encodeSparse2(grid, bpp)
+	default:
+		return nil, fmt.Errorf("codificação desconhecida: %d", enc)
+	}
+	if enc&0x80 != 0 {
+		payload = zlibCompress(payload)
+	}
+	return payload, nil
+}
+
 func bestEncoding(grid *VoxelGrid, bpp uint8) encoded {
 	candidates := []encoded{
 		{encoding: encDense, payload: encodeDense(grid, bpp)},
@@ -0,0 +1,487 @@
+package vopl
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Expr is a compiled fill formula evaluated once per voxel, e.g.
+//
+//	x*x + z*z < 36 && y < 8 ? 12 : 0
+//
+// Values are float64; comparisons and logical operators yield 1 or 0, and any
+// non-zero value is true. The result is rounded and clamped to a palette index in
+// [0,255] (NaN gives 0).
+//
+// Variables: x, y, z (world voxel coordinates), lx, ly, lz (coordinates inside the
+// chunk), pi. Operators, loosest first: ?:, ||, &&, == !=, < <= > >=, + -, * / %,
+// unary - + !. A #RRGGBB literal evaluates to the nearest palette index.
+//
+// Functions: abs, floor, ceil, round, sqrt, sin, cos, min, max, pow, clamp(v,lo,hi),
+// mod (always non-negative for a positive divisor), noise(x,y,z) (Perlin, about
+// [-1,1]), fbm(x,y,z,octaves) (octaves clamped to 1..MaxFBMOctaves), rgb(r,g,b)
+// (nearest palette index for 0..255 components) and pick(i, c0, c1, ...) (the i-th
+// value, i clamped to the list).
+type Expr struct {
+	src  string
+	root exprFn
+}
+
+type exprVars struct {
+	x, y, z, lx, ly, lz float64
+}
+
+type exprFn func(v *exprVars) float64
+
+// ParseExpr compiles a fill formula (see Expr).
+func ParseExpr(src string) (*Expr, error) {
+	toks, err := tokenizeExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{toks: toks}
+	root, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("expr: unexpected %q at %d", t.text, t.pos)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// String returns the source of the formula.
+func (e *Expr) String() string { return e.src }
+
+// Eval evaluates the formula at world position (x,y,z).
+func (e *Expr) Eval(x, y, z int) float64 {
+	_, l := ChunkOf(x, y, z)
+	return e.root(&exprVars{
+		x: float64(x), y: float64(y), z: float64(z),
+		lx: float64(l[0]), ly: float64(l[1]), lz: float64(l[2]),
+	})
+}
+
+// Color evaluates the formula at world position (x,y,z) as a palette index.
+func (e *Expr) Color(x, y, z int) uint8 {
+	v := math.Round(e.Eval(x, y, z))
+	if math.IsNaN(v) || v <= 0 {
+		return 0
+	}
+	return uint8(min(v, 255))
+}
+
+// FillGrid overwrites every cell of g with the formula evaluated at origin plus the
+// local cell position and returns the number of non-empty cells.
+func (e *Expr) FillGrid(g *VoxelGrid, origin [3]int) int {
+	n := 0
+	for v := range g.All() {
+		c := e.Color(origin[0]+v.X, origin[1]+v.Y, origin[2]+v.Z)
+		g[v.Y][v.X][v.Z] = c
+		if c != 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// FillRegion writes the formula over the half-open world box b into m (a ChunkMap or
+// World, which create chunks as needed) and returns the number of non-empty cells
+// written.
+func (e *Expr) FillRegion(m VoxelWriter, b Box) (int, error) {
+	for _, corner := range [2][3]int{b.Min, {b.Max[0] - 1, b.Max[1] - 1, b.Max[2] - 1}} {
+		if c, _ := ChunkOf(corner[0], corner[1], corner[2]); !c.Valid() {
+			return 0, fmt.Errorf("region %v-%v leaves the valid chunk range", b.Min, b.Max)
+		}
+	}
+	n := 0
+	for y := b.Min[1]; y < b.Max[1]; y++ {
+		for x := b.Min[0]; x < b.Max[0]; x++ {
+			for z := b.Min[2]; z < b.Max[2]; z++ {
+				c := e.Color(x, y, z)
+				m.Set(x, y, z, c)
+				if c != 0 {
+					n++
+				}
+			}
+		}
+	}
+	return n, nil
+}
+
+type exprTokKind int
+
+const (
+	tokEOF exprTokKind = iota
+	tokNum
+	tokIdent
+	tokOp
+)
+
+type exprTok struct {
+	kind exprTokKind
+	text string
+	num  float64
+	pos  int
+}
+
+// exprOps lists operators, two-character ones first so they win over their prefixes.
+var exprOps = []string{"&&", "||", "==", "!=", "<=", ">=", "+", "-", "*", "/", "%", "<", ">", "!", "?", ":", "(", ")", ","}
+
+func tokenizeExpr(src string) ([]exprTok, error) {
+	var toks []exprTok
+	i := 0
+next:
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			continue
+		case c == '#':
+			j := i + 1
+			for j < len(src) && isHexDigit(src[j]) {
+				j++
+			}
+			rgb, err := ParseHexColor(src[i:j])
+			if err != nil || j-i != 7 {
+				return nil, fmt.Errorf("expr: invalid color literal %q at %d", src[i:j], i)
+			}
+			idx := NearestPaletteIndex(uint8(rgb[0]*255+0.5), uint8(rgb[1]*255+0.5), uint8(rgb[2]*255+0.5))
+			toks = append(toks, exprTok{kind: tokNum, text: src[i:j], num: float64(idx), pos: i})
+			i = j
+			continue
+		case c >= '0' && c <= '9' || c == '.':
+			j := i
+			for j < len(src) && (src[j] >= '0' && src[j] <= '9' || src[j] == '.') {
+				j++
+			}
+			f, err := strconv.ParseFloat(src[i:j], 64)
+			if err != nil {
+				return nil, fmt.Errorf("expr: invalid number %q at %d", src[i:j], i)
+			}
+			toks = append(toks, exprTok{kind: tokNum, text: src[i:j], num: f, pos: i})
+			i = j
+			continue
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			j := i
+			for j < len(src) && (src[j] == '_' || src[j] >= 'a' && src[j] <= 'z' || src[j] >= 'A' && src[j] <= 'Z' || src[j] >= '0' && src[j] <= '9') {
+				j++
+			}
+			toks = append(toks, exprTok{kind: tokIdent, text: src[i:j], pos: i})
+			i = j
+			continue
+		}
+		for _, op := range exprOps {
+			if strings.HasPrefix(src[i:], op) {
+				toks = append(toks, exprTok{kind: tokOp, text: op, pos: i})
+				i += len(op)
+				continue next
+			}
+		}
+		return nil, fmt.Errorf("expr: unexpected character %q at %d", c, i)
+	}
+	return append(toks, exprTok{kind: tokEOF, text: "end of input", pos: len(src)}), nil
+}
+
+func isHexDigit(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+type exprParser struct {
+	toks []exprTok
+	pos  int
+}
+
+func (p *exprParser) peek() exprTok { return p.toks[p.pos] }
+
+// accept consumes the next token if it is the operator op.
+func (p *exprParser) accept(op string) bool {
+	if t := p.peek(); t.kind == tokOp && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) expect(op string) error {
+	if !p.accept(op) {
+		t := p.peek()
+		return fmt.Errorf("expr: expected %q at %d, got %q", op, t.pos, t.text)
+	}
+	return nil
+}
+
+func (p *exprParser) ternary() (exprFn, error) {
+	cond, err := p.binary(0)
+	if err != nil || !p.accept("?") {
+		return cond, err
+	}
+	a, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	b, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	return func(v *exprVars) float64 {
+		if cond(v) != 0 {
+			return a(v)
+		}
+		return b(v)
+	}, nil
+}
+
+// exprLevels groups binary operators by precedence, loosest first.
+var exprLevels = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *exprParser) binary(level int) (exprFn, error) {
+	if level == len(exprLevels) {
+		return p.unary()
+	}
+	lhs, err := p.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind != tokOp || !containsOp(exprLevels[level], t.text) {
+			return lhs, nil
+		}
+		p.pos++
+		rhs, err := p.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		lhs = binaryOp(t.text, lhs, rhs)
+	}
+}
+
+func containsOp(ops []string, op string) bool {
+	for _, o := range ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+func boolf(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func binaryOp(op string, a, b exprFn) exprFn {
+	switch op {
+	case "||":
+		return func(v *exprVars) float64 { return boolf(a(v) != 0 || b(v) != 0) }
+	case "&&":
+		return func(v *exprVars) float64 { return boolf(a(v) != 0 && b(v) != 0) }
+	case "==":
+		return func(v *exprVars) float64 { return boolf(a(v) == b(v)) }
+	case "!=":
+		return func(v *exprVars) float64 { return boolf(a(v) != b(v)) }
+	case "<":
+		return func(v *exprVars) float64 { return boolf(a(v) < b(v)) }
+	case "<=":
+		return func(v *exprVars) float64 { return boolf(a(v) <= b(v)) }
+	case ">":
+		return func(v *exprVars) float64 { return boolf(a(v) > b(v)) }
+	case ">=":
+		return func(v *exprVars) float64 { return boolf(a(v) >= b(v)) }
+	case "+":
+		return func(v *exprVars) float64 { return a(v) + b(v) }
+	case "-":
+		return func(v *exprVars) float64 { return a(v) - b(v) }
+	case "*":
+		return func(v *exprVars) float64 { return a(v) * b(v) }
+	case "/":
+		return func(v *exprVars) float64 { return a(v) / b(v) }
+	default: // "%"
+		return func(v *exprVars) float64 { return math.Mod(a(v), b(v)) }
+	}
+}
+
+func (p *exprParser) unary() (exprFn, error) {
+	switch {
+	case p.accept("-"):
+		a, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(v *exprVars) float64 { return -a(v) }, nil
+	case p.accept("+"):
+		return p.unary()
+	case p.accept("!"):
+		a, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(v *exprVars) float64 { return boolf(a(v) == 0) }, nil
+	}
+	return p.primary()
+}
+
+func (p *exprParser) primary() (exprFn, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokNum:
+		p.pos++
+		n := t.num
+		return func(*exprVars) float64 { return n }, nil
+	case tokIdent:
+		p.pos++
+		if p.accept("(") {
+			return p.call(t)
+		}
+		return exprVariable(t)
+	case tokOp:
+		if p.accept("(") {
+			e, err := p.ternary()
+			if err != nil {
+				return nil, err
+			}
+			return e, p.expect(")")
+		}
+	}
+	return nil, fmt.Errorf("expr: unexpected %q at %d", t.text, t.pos)
+}
+
+func exprVariable(t exprTok) (exprFn, error) {
+	switch t.text {
+	case "x":
+		return func(v *exprVars) float64 { return v.x }, nil
+	case "y":
+		return func(v *exprVars) float64 { return v.y }, nil
+	case "z":
+		return func(v *exprVars) float64 { return v.z }, nil
+	case "lx":
+		return func(v *exprVars) float64 { return v.lx }, nil
+	case "ly":
+		return func(v *exprVars) float64 { return v.ly }, nil
+	case "lz":
+		return func(v *exprVars) float64 { return v.lz }, nil
+	case "pi":
+		return func(*exprVars) float64 { return math.Pi }, nil
+	}
+	return nil, fmt.Errorf("expr: unknown variable %q at %d", t.text, t.pos)
+}
+
+// exprFunc describes a built-in function; maxArgs < 0 means variadic.
+type exprFunc struct {
+	minArgs, maxArgs int
+	fn               func(args []float64) float64
+}
+
+var exprFuncs = map[string]exprFunc{
+	"abs":   {1, 1, func(a []float64) float64 { return math.Abs(a[0]) }},
+	"floor": {1, 1, func(a []float64) float64 { return math.Floor(a[0]) }},
+	"ceil":  {1, 1, func(a []float64) float64 { return math.Ceil(a[0]) }},
+	"round": {1, 1, func(a []float64) float64 { return math.Round(a[0]) }},
+	"sqrt":  {1, 1, func(a []float64) float64 { return math.Sqrt(a[0]) }},
+	"sin":   {1, 1, func(a []float64) float64 { return math.Sin(a[0]) }},
+	"cos":   {1, 1, func(a []float64) float64 { return math.Cos(a[0]) }},
+	"pow":   {2, 2, func(a []float64) float64 { return math.Pow(a[0], a[1]) }},
+	"min": {1, -1, func(a []float64) float64 {
+		m := a[0]
+		for _, v := range a[1:] {
+			m = math.Min(m, v)
+		}
+		return m
+	}},
+	"max": {1, -1, func(a []float64) float64 {
+		m := a[0]
+		for _, v := range a[1:] {
+			m = math.Max(m, v)
+		}
+		return m
+	}},
+	"clamp": {3, 3, func(a []float64) float64 { return math.Min(math.Max(a[0], a[1]), a[2]) }},
+	"mod": {2, 2, func(a []float64) float64 {
+		m := math.Mod(a[0], a[1])
+		if m < 0 {
+			m += math.Abs(a[1])
+		}
+		return m
+	}},
+	"noise": {3, 3, func(a []float64) float64 { return Noise3(a[0], a[1], a[2]) }},
+	"fbm": {4, 4, func(a []float64) float64 {
+		o := a[3]
+		if math.IsNaN(o) {
+			o = 1
+		}
+		return FBM3(a[0], a[1], a[2], int(math.Min(math.Max(o, 1), MaxFBMOctaves)))
+	}},
+	"rgb": {3, 3, func(a []float64) float64 {
+		var c [3]uint8
+		for i := range 3 {
+			c[i] = uint8(math.Min(math.Max(math.Round(a[i]), 0), 255))
+		}
+		return float64(NearestPaletteIndex(c[0], c[1], c[2]))
+	}},
+	"pick": {2, -1, func(a []float64) float64 {
+		i := a[0]
+		if math.IsNaN(i) {
+			i = 0
+		}
+		return a[1+int(math.Min(math.Max(math.Floor(i), 0), float64(len(a)-2)))]
+	}},
+}
+
+func (p *exprParser) call(name exprTok) (exprFn, error) {
+	f, ok := exprFuncs[name.text]
+	if !ok {
+		return nil, fmt.Errorf("expr: unknown function %q at %d", name.text, name.pos)
+	}
+	var args []exprFn
+	if !p.accept(")") {
+		for {
+			a, err := p.ternary()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, a)
+			if p.accept(")") {
+				break
+			}
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(args) < f.minArgs || (f.maxArgs >= 0 && len(args) > f.maxArgs) {
+		return nil, fmt.Errorf("expr: %s takes %s arguments, got %d", name.text, arityString(f), len(args))
+	}
+	return func(v *exprVars) float64 {
+		vals := make([]float64, len(args))
+		for i, a := range args {
+			vals[i] = a(v)
+		}
+		return f.fn(vals)
+	}, nil
+}
+
+func arityString(f exprFunc) string {
+	switch {
+	case f.maxArgs < 0:
+		return fmt.Sprintf("at least %d", f.minArgs)
+	case f.minArgs == f.maxArgs:
+		return strconv.Itoa(f.minArgs)
+	}
+	return fmt.Sprintf("%d to %d", f.minArgs, f.maxArgs)
+}
@@ -15,14 +15,7 @@ var directions = []dirSpec{
 	{[3]float32{0, 0, -1}, 0, 1, [3]int{1, 0, 0}, [3]int{0, 1, 0}},
 }
 
-func getVoxel(grid *VoxelGrid, x, y, z int) uint8 {
-	if x < 0 || x >= Width || y < 0 || y >= Height || z < 0 || z >= Depth {
-		return 0
-	}
-	return grid[y][x][z]
-}
-
-func addQuad(mesh *Mesh, dir dirSpec, start [3]int, w, h int, color uint8, perp int) {
+func addQuad(mesh *Mesh, dir dirSpec, start [3]int, w, h int, color, light uint8, perp int) {
 	base := [3]float32{}
 	base[perp] = float32(start[0])
 	if dir.normal[perp] > 0 {
@@ -32,10 +25,10 @@ func addQuad(mesh *Mesh, dir dirSpec, start [3]int, w, h int, color uint8, perp
 	base[dir.v] = float32(start[2])
 
 	verts := [4]Vertex{
-		{Position: base, Color: color},
-		{Position: [3]float32{base[0] + float32(dir.du[0]*h), base[1] + float32(dir.du[1]*h), base[2] + float32(dir.du[2]*h)}, Color: color},
-		{Position: [3]float32{base[0] + float32(dir.du[0]*h) + float32(dir.dv[0]*w), base[1] + float32(dir.du[1]*h) + float32(dir.dv[1]*w), base[2] + float32(dir.du[2]*h) + float32(dir.dv[2]*w)}, Color: color},
-		{Position: [3]float32{base[0] + float32(dir.dv[0]*w), base[1] + float32(dir.dv[1]*w), base[2] + float32(dir.dv[2]*w)}, Color: color},
+		{Position: base, Color: color, Light: light},
+		{Position: [3]float32{base[0] + float32(dir.du[0]*h), base[1] + float32(dir.du[1]*h), base[2] + float32(dir.du[2]*h)}, Color: color, Light: light},
+		{Position: [3]float32{base[0] + float32(dir.du[0]*h) + float32(dir.dv[0]*w), base[1] + float32(dir.du[1]*h) + float32(dir.dv[1]*w), base[2] + float32(dir.du[2]*h) + float32(dir.dv[2]*w)}, Color: color, Light: light},
+		{Position: [3]float32{base[0] + float32(dir.dv[0]*w), base[1] + float32(dir.dv[1]*w), base[2] + float32(dir.dv[2]*w)}, Color: color, Light: light},
 	}
 
 	swap := (dir.normal[perp] < 0) != (perp == 1)
@@ -48,62 +41,74 @@ func addQuad(mesh *Mesh, dir dirSpec, start [3]int, w, h int, color uint8, perp
 	mesh.Indices = append(mesh.Indices, baseIdx, baseIdx+1, baseIdx+2, baseIdx, baseIdx+2, baseIdx+3)
 }
 
+// GenerateMesh builds a greedy quad mesh of grid. A face is emitted when its neighbour
+// is outside the grid or exterior air (see ExteriorAir); faces bordering sealed
+// interior cavities can never be seen and are skipped.
 func GenerateMesh(grid *VoxelGrid) *Mesh {
-	mesh := &Mesh{}
+	return generateMesh(grid, nil)
+}
+
+// GenerateLitMesh is GenerateMesh with baked lighting: each face takes the light level
+// of the cell it faces (or the voxel's own emission, if brighter), faces are only merged
+// when both color and light match, and the mesh is marked Lit.
+func GenerateLitMesh(grid *VoxelGrid, light *LightMap) *Mesh {
+	return generateMesh(grid, light)
+}
+
+func generateMesh(grid *VoxelGrid, light *LightMap) *Mesh {
+	mesh := &Mesh{Lit: light != nil}
 	dims := [3]int{Width, Height, Depth}
+	occ := grid.Occupancy()
+	// a face shows when the cell it looks into is outside the grid or exterior air
+	air := occ.Exterior()
 
-	for _, dir := range directions {
+	for di, dir := range directions {
+		faces := occ.And(air.Neighbors(Face(di), true))
+		if faces.IsEmpty() {
+			continue
+		}
 		perp := 3 - dir.u - dir.v
+		du, dv := dims[dir.u], dims[dir.v]
 
-		for p := 0; p < dims[perp]; p++ {
-			mask := make([][]uint8, dims[dir.u])
-			visited := make([][]bool, dims[dir.u])
-			for i := range mask {
-				mask[i] = make([]uint8, dims[dir.v])
-				visited[i] = make([]bool, dims[dir.v])
+		// mask holds the face key of every slice: color in the low byte, light level above it.
+		mask := make([]uint16, dims[perp]*du*dv)
+		used := make([]bool, dims[perp])
+		for pos := range faces.Cells() {
+			p := pos[perp]
+			key := uint16(grid[pos[1]][pos[0]][pos[2]])
+			if light != nil {
+				adj := pos
+				adj[perp] += int(dir.normal[perp])
+				l := max(light.At(adj[0], adj[1], adj[2]), light.At(pos[0], pos[1], pos[2]))
+				key |= uint16(l) << 8
 			}
+			mask[(p*du+pos[dir.u])*dv+pos[dir.v]] = key
+			used[p] = true
+		}
 
-			for u := 0; u < dims[dir.u]; u++ {
-				for v := 0; v < dims[dir.v]; v++ {
-					pos := [3]int{}
-					pos[dir.u] = u
-					pos[dir.v] = v
-					pos[perp] = p
-
-					voxel := getVoxel(grid, pos[0], pos[1], pos[2])
-					if voxel == 0 {
-						continue
-					}
-
-					adj := pos
-					if dir.normal[perp] < 0 {
-						adj[perp] = p - 1
-					} else {
-						adj[perp] = p + 1
-					}
-
-					if adj[perp] < 0 || adj[perp] >= dims[perp] || getVoxel(grid, adj[0], adj[1], adj[2]) == 0 {
-						mask[u][v] = voxel
-					}
-				}
+		visited := make([]bool, du*dv)
+		for p := range dims[perp] {
+			if !used[p] {
+				continue
 			}
-
-			for u := 0; u < dims[dir.u]; u++ {
-				for v := 0; v < dims[dir.v]; {
-					if mask[u][v] == 0 || visited[u][v] {
+			slice := mask[p*du*dv : (p+1)*du*dv]
+			clear(visited)
+			for u := 0; u < du; u++ {
+				for v := 0; v < dv; {
+					if slice[u*dv+v] == 0 || visited[u*dv+v] {
 						v++
 						continue
 					}
-					color := mask[u][v]
+					key := slice[u*dv+v]
 					width := 1
-					for w := v + 1; w < dims[dir.v] && mask[u][w] == color && !visited[u][w]; w++ {
+					for w := v + 1; w < dv && slice[u*dv+w] == key && !visited[u*dv+w]; w++ {
 						width++
 					}
 					height := 1
 					stop := false
-					for h := u + 1; h < dims[dir.u] && !stop; h++ {
+					for h := u + 1; h < du && !stop; h++ {
 						for w := v; w < v+width; w++ {
-							if mask[h][w] != color || visited[h][w] {
+							if slice[h*dv+w] != key || visited[h*dv+w] {
 								stop = true
 								break
 							}
@@ -114,10 +119,10 @@ func GenerateMesh(grid *VoxelGrid) *Mesh {
 					}
 					for hu := u; hu < u+height; hu++ {
 						for hv := v; hv < v+width; hv++ {
-							visited[hu][hv] = true
+							visited[hu*dv+hv] = true
 						}
 					}
-					addQuad(mesh, dir, [3]int{p, u, v}, width, height, color, perp)
+					addQuad(mesh, dir, [3]int{p, u, v}, width, height, uint8(key), uint8(key>>8), perp)
 					v += width
 				}
 			}
@@ -1,5 +1,7 @@
 package vopl
 
+import "iter"
+
 const (
 	Height = 16
 	Width  = 16
@@ -8,3 +10,178 @@ const (
 
 // VoxelGrid[y][x][z]
 type VoxelGrid [Height][Width][Depth]uint8
+
+// Voxel is a single cell of a VoxelGrid as yielded by its iterators.
+type Voxel struct {
+	X, Y, Z int
+	Color   uint8
+}
+
+// InBounds reports whether (x,y,z) addresses a cell inside a VoxelGrid.
+func InBounds(x, y, z int) bool {
+	return x >= 0 && x < Width && y >= 0 && y < Height && z >= 0 && z < Depth
+}
+
+// LinearIndex returns the index used by the updates JSON format: x + y*W + z*W*H.
+func LinearIndex(x, y, z int) int {
+	return x + y*Width + z*Width*Height
+}
+
+// XYZFromLinearIndex is the inverse of LinearIndex.
+func XYZFromLinearIndex(index int) (x, y, z int) {
+	wh := Width * Height
+	z = index / wh
+	rem := index - z*wh
+	y = rem / Width
+	x = rem - y*Width
+	return
+}
+
+// Get returns the palette index at (x,y,z), or 0 when the position is outside the grid.
+func (g *VoxelGrid) Get(x, y, z int) uint8 {
+	if !InBounds(x, y, z) {
+		return 0
+	}
+	return g[y][x][z]
+}
+
+// Set writes color at (x,y,z). It reports false and leaves the grid untouched
+// when the position is outside the grid.
+func (g *VoxelGrid) Set(x, y, z int, color uint8) bool {
+	if !InBounds(x, y, z) {
+		return false
+	}
+	g[y][x][z] = color
+	return true
+}
+
+// FillBox sets every cell in the half-open box [x0,x1)×[y0,y1)×[z0,z1) to color.
+// The box is clipped to the grid; swapped bounds are normalized. It returns the
+// number of cells written.
+func (g *VoxelGrid) FillBox(x0, y0, z0, x1, y1, z1 int, color uint8) int {
+	if x1 < x0 {
+		x0, x1 = x1, x0
+	}
+	if y1 < y0 {
+		y0, y1 = y1, y0
+	}
+	if z1 < z0 {
+		z0, z1 = z1, z0
+	}
+	x0, x1 = clampRange(x0, x1, Width)
+	y0, y1 = clampRange(y0, y1, Height)
+	z0, z1 = clampRange(z0, z1, Depth)
+	n := 0
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			for z := z0; z < z1; z++ {
+				g[y][x][z] = color
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func clampRange(lo, hi, size int) (int, int) {
+	if lo < 0 {
+		lo = 0
+	}
+	if hi > size {
+		hi = size
+	}
+	if hi < lo {
+		hi = lo
+	}
+	return lo, hi
+}
+
+// ReplaceColor rewrites every cell equal to from with to and returns how many changed.
+func (g *VoxelGrid) ReplaceColor(from, to uint8) int {
+	if from == to {
+		return 0
+	}
+	n := 0
+	for y := range Height {
+		for x := range Width {
+			for z := range Depth {
+				if g[y][x][z] == from {
+					g[y][x][z] = to
+					n++
+				}
+			}
+		}
+	}
+	return n
+}
+
+// CountNonZero returns the number of occupied (non-zero) cells.
+func (g *VoxelGrid) CountNonZero() int {
+	n := 0
+	for y := range Height {
+		for x := range Width {
+			for z := range Depth {
+				if g[y][x][z] != 0 {
+					n++
+				}
+			}
+		}
+	}
+	return n
+}
+
+// IsEmpty reports whether every cell is 0.
+func (g *VoxelGrid) IsEmpty() bool {
+	return *g == VoxelGrid{}
+}
+
+// All yields every cell, including empty ones, in storage order: y outermost,
+// then x, then z innermost (matching VoxelGrid[y][x][z]).
+func (g *VoxelGrid) All() iter.Seq[Voxel] {
+	return func(yield func(Voxel) bool) {
+		for y := range Height {
+			for x := range Width {
+				for z := range Depth {
+					if !yield(Voxel{X: x, Y: y, Z: z, Color: g[y][x][z]}) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+// Occupied yields only non-zero cells, in the same y, x, z order as All.
+func (g *VoxelGrid) Occupied() iter.Seq[Voxel] {
+	return func(yield func(Voxel) bool) {
+		for y := range Height {
+			for x := range Width {
+				for z := range Depth {
+					if c := g[y][x][z]; c != 0 {
+						if !yield(Voxel{X: x, Y: y, Z: z, Color: c}) {
+							return
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// OccupiedLinear yields non-zero cells in ascending LinearIndex order
+// (x fastest, then y, then z), which is the order used by updates JSON documents.
+func (g *VoxelGrid) OccupiedLinear() iter.Seq[Voxel] {
+	return func(yield func(Voxel) bool) {
+		for z := range Depth {
+			for y := range Height {
+				for x := range Width {
+					if c := g[y][x][z]; c != 0 {
+						if !yield(Voxel{X: x, Y: y, Z: z, Color: c}) {
+							return
+						}
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package vopl
+
+// AirMask marks cells indexed [y][x][z] like VoxelGrid.
+type AirMask [Height][Width][Depth]bool
+
+// ExteriorAir flood-fills the empty cells of g from outside the grid and returns
+// the cells reached. Everything beyond the grid edge counts as outside, so any
+// empty cell on the boundary seeds the fill. Empty cells left unmarked form
+// sealed cavities that no face can reveal. Connectivity is face-to-face (6).
+func ExteriorAir(g *VoxelGrid) *AirMask {
+	air := new(AirMask)
+	for c := range g.Occupancy().Exterior().Cells() {
+		air[c[1]][c[0]][c[2]] = true
+	}
+	return air
+}
+
+// visibleFrom reports whether a face looking from an occupied cell into (x,y,z) can be
+// seen: the neighbour is outside the grid or exterior air.
+func (m *AirMask) visibleFrom(x, y, z int) bool {
+	if !InBounds(x, y, z) {
+		return true
+	}
+	return m[y][x][z]
+}
+
+// Hollow removes occupied voxels that lie deeper than thickness cells (face steps)
+// from exterior air and returns how many were removed. A thickness below 1 is treated
+// as 1, the thinnest shell that still hides the interior. Because GenerateMesh only
+// emits faces towards exterior air, the mesh of the hollowed grid is identical to
+// the mesh of the original.
+func (g *VoxelGrid) Hollow(thickness int) int {
+	if thickness < 1 {
+		thickness = 1
+	}
+	air := ExteriorAir(g)
+	// depth[y][x][z] is the face-step distance from exterior air; 0 means not yet reached.
+	var depth [Height][Width][Depth]int
+	queue := make([][3]int, 0, 256)
+	for v := range g.Occupied() {
+		for _, d := range faceOffsets {
+			if air.visibleFrom(v.X+d[0], v.Y+d[1], v.Z+d[2]) {
+				depth[v.Y][v.X][v.Z] = 1
+				queue = append(queue, [3]int{v.X, v.Y, v.Z})
+				break
+			}
+		}
+	}
+	for head := 0; head < len(queue); head++ {
+		p := queue[head]
+		dp := depth[p[1]][p[0]][p[2]]
+		if dp >= thickness {
+			continue
+		}
+		for _, d := range faceOffsets {
+			nx, ny, nz := p[0]+d[0], p[1]+d[1], p[2]+d[2]
+			if !InBounds(nx, ny, nz) || g[ny][nx][nz] == 0 || depth[ny][nx][nz] != 0 {
+				continue
+			}
+			depth[ny][nx][nz] = dp + 1
+			queue = append(queue, [3]int{nx, ny, nz})
+		}
+	}
+	removed := 0
+	for y := range Height {
+		for x := range Width {
+			for z := range Depth {
+				if g[y][x][z] != 0 && depth[y][x][z] == 0 {
+					g[y][x][z] = 0
+					removed++
+				}
+			}
+		}
+	}
+	return removed
+}
@@ -0,0 +1,95 @@
+package vopl
+
+import "math"
+
+// MaxLight is the brightest light level.
+const MaxLight = 15
+
+// LightOptions configures ComputeLight.
+type LightOptions struct {
+	// Sky is the sky light level (0..MaxLight). Sky light falls straight down through
+	// empty cells without dimming and leaks in from the chunk's sides and bottom one
+	// level dimmer, as if the chunk stood alone in open air.
+	Sky uint8
+	// Emissive maps palette indices to the light level their voxels emit.
+	Emissive map[uint8]uint8
+}
+
+// DefaultLightOptions enables full sky light and no emissive entries.
+func DefaultLightOptions() LightOptions { return LightOptions{Sky: MaxLight} }
+
+// LightMap holds light levels (0..MaxLight) indexed [y][x][z] like VoxelGrid.
+// Empty cells carry propagated light; emissive voxels carry their own level; other
+// solid cells are 0.
+type LightMap struct {
+	Levels [Height][Width][Depth]uint8
+	// Sky is the level read outside the chunk.
+	Sky uint8
+}
+
+// At returns the light level at (x,y,z); positions outside the chunk read as Sky.
+func (m *LightMap) At(x, y, z int) uint8 {
+	if !InBounds(x, y, z) {
+		return m.Sky
+	}
+	return m.Levels[y][x][z]
+}
+
+// ComputeLight flood-fills light through the empty cells of g, Minecraft style: every
+// face step away from a source dims light by one level, and solid voxels block it.
+// Sources are sky light (see LightOptions.Sky) and emissive voxels.
+func ComputeLight(g *VoxelGrid, opts LightOptions) *LightMap {
+	sky := min(opts.Sky, MaxLight)
+	m := &LightMap{Sky: sky}
+	queue := make([][3]int, 0, 256)
+	raise := func(x, y, z int, level uint8) {
+		if level > m.Levels[y][x][z] {
+			m.Levels[y][x][z] = level
+			queue = append(queue, [3]int{x, y, z})
+		}
+	}
+	for v := range g.Occupied() {
+		if e := opts.Emissive[v.Color]; e > 0 {
+			raise(v.X, v.Y, v.Z, min(e, MaxLight))
+		}
+	}
+	if sky > 0 {
+		for x := range Width {
+			for z := range Depth {
+				for y := Height - 1; y >= 0 && g[y][x][z] == 0; y-- {
+					raise(x, y, z, sky)
+				}
+			}
+		}
+		for v := range g.All() {
+			if v.Color != 0 {
+				continue
+			}
+			if v.X == 0 || v.Z == 0 || v.Y == 0 || v.X == Width-1 || v.Z == Depth-1 {
+				raise(v.X, v.Y, v.Z, sky-1)
+			}
+		}
+	}
+	// Levels only ever rise, so a FIFO queue converges; stale entries just re-spread
+	// a level that is already in place.
+	for i := 0; i < len(queue); i++ {
+		p := queue[i]
+		level := m.Levels[p[1]][p[0]][p[2]]
+		if level <= 1 {
+			continue
+		}
+		for _, d := range faceOffsets {
+			x, y, z := p[0]+d[0], p[1]+d[1], p[2]+d[2]
+			if InBounds(x, y, z) && g[y][x][z] == 0 {
+				raise(x, y, z, level-1)
+			}
+		}
+	}
+	return m
+}
+
+// LightBrightness maps a light level to a color multiplier in (0,1]: each level below
+// MaxLight dims by 20%.
+func LightBrightness(level uint8) float32 {
+	return float32(math.Pow(0.8, float64(MaxLight-min(level, MaxLight))))
+}
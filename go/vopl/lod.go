@@ -0,0 +1,137 @@
+package vopl
+
+import "fmt"
+
+// LODMode selects how a 2×2×2 block is reduced to a single cell.
+type LODMode uint8
+
+const (
+	// LODMajority keeps a block when at least half of its cells are occupied and
+	// colors it with the most frequent palette index.
+	LODMajority LODMode = 0
+	// LODVisible keeps a block when any cell is occupied and prefers the most frequent
+	// color among cells with a face exposed to exterior air, so silhouettes and
+	// surface colors survive reduction.
+	LODVisible LODMode = 1
+)
+
+// ParseLODMode accepts "majority" or "visible".
+func ParseLODMode(s string) (LODMode, error) {
+	switch s {
+	case "majority":
+		return LODMajority, nil
+	case "visible":
+		return LODVisible, nil
+	}
+	return 0, fmt.Errorf("unknown LOD mode: %q (use majority or visible)", s)
+}
+
+// MaxLODLevel is the coarsest level: a single cell (16 >> 4 == 1).
+const MaxLODLevel = 4
+
+// LODLevel is one reduction of a grid. Only the low corner Size×Size×Size of Grid is
+// used; the rest is always 0. Each cell covers Scale×Scale×Scale original voxels.
+type LODLevel struct {
+	Level int
+	Size  int
+	Scale int
+	Grid  VoxelGrid
+}
+
+// Downsample halves the used region of a level-N grid (size cells per side) into a
+// level-N+1 grid of size/2 cells per side stored in the low corner of the result.
+func Downsample(g *VoxelGrid, size int, mode LODMode) *VoxelGrid {
+	var air *AirMask
+	if mode == LODVisible {
+		air = ExteriorAir(g)
+	}
+	half := size / 2
+	var out VoxelGrid
+	for y := range half {
+		for x := range half {
+			for z := range half {
+				var all, visible [256]int
+				occupied := 0
+				for dy := range 2 {
+					for dx := range 2 {
+						for dz := range 2 {
+							cx, cy, cz := 2*x+dx, 2*y+dy, 2*z+dz
+							c := g[cy][cx][cz]
+							if c == 0 {
+								continue
+							}
+							occupied++
+							all[c]++
+							if air != nil && exposed(air, cx, cy, cz) {
+								visible[c]++
+							}
+						}
+					}
+				}
+				switch mode {
+				case LODMajority:
+					if occupied >= 4 {
+						out[y][x][z] = mostFrequent(&all)
+					}
+				case LODVisible:
+					if occupied > 0 {
+						if c := mostFrequent(&visible); c != 0 {
+							out[y][x][z] = c
+						} else {
+							out[y][x][z] = mostFrequent(&all)
+						}
+					}
+				}
+			}
+		}
+	}
+	return &out
+}
+
+func exposed(air *AirMask, x, y, z int) bool {
+	for _, d := range faceOffsets {
+		if air.visibleFrom(x+d[0], y+d[1], z+d[2]) {
+			return true
+		}
+	}
+	return false
+}
+
+// mostFrequent returns the non-zero index with the highest count, preferring the
+// lowest index on ties, or 0 when all counts are zero.
+func mostFrequent(counts *[256]int) uint8 {
+	best := 0
+	for c := 1; c < len(counts); c++ {
+		if counts[c] > counts[best] {
+			best = c
+		}
+	}
+	return uint8(best)
+}
+
+// BuildLODs returns levels 0 (the original grid) through MaxLODLevel.
+func BuildLODs(g *VoxelGrid, mode LODMode) []LODLevel {
+	levels := make([]LODLevel, 0, MaxLODLevel+1)
+	levels = append(levels, LODLevel{Level: 0, Size: Width, Scale: 1, Grid: *g})
+	cur := g
+	for lvl := 1; lvl <= MaxLODLevel; lvl++ {
+		size := Width >> (lvl - 1)
+		cur = Downsample(cur, size, mode)
+		levels = append(levels, LODLevel{Level: lvl, Size: size / 2, Scale: 1 << lvl, Grid: *cur})
+	}
+	return levels
+}
+
+// GenerateMeshLOD meshes a LOD level grid and scales vertex positions by 2^level so the
+// result covers the same 16³ volume as the original chunk.
+func GenerateMeshLOD(grid *VoxelGrid, level int) *Mesh {
+	mesh := GenerateMesh(grid)
+	s := float32(int(1) << level)
+	for i := range mesh.Vertices {
+		p := &mesh.Vertices[i].Position
+		p[0] *= s
+		p[1] *= s
+		p[2] *= s
+	}
+	return mesh
+}
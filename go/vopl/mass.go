@@ -0,0 +1,63 @@
+package vopl
+
+// MassProps holds the mass properties of a grid treated as a set of unit cubes.
+// Coordinates are in voxel units with cell (x,y,z) spanning [x,x+1)×[y,y+1)×[z,z+1).
+type MassProps struct {
+	// Volume is the occupied volume (number of voxels).
+	Volume float64 `json:"volume"`
+	// Mass is the sum of voxel densities.
+	Mass float64 `json:"mass"`
+	// CenterOfMass is [x,y,z]; zero when Mass is zero.
+	CenterOfMass [3]float64 `json:"centerOfMass"`
+	// Inertia is the inertia tensor about CenterOfMass, rows/columns ordered x, y, z.
+	Inertia [3][3]float64 `json:"inertia"`
+}
+
+// MassProperties computes volume, mass, center of mass and inertia tensor of g.
+// densities gives the mass of one voxel per palette index; nil or missing entries
+// default to 1.
+func MassProperties(g *VoxelGrid, densities map[uint8]float64) MassProps {
+	var p MassProps
+	var first [3]float64     // Σ m·c
+	var second [3][3]float64 // Σ m·c·cᵀ
+	for v := range g.Occupied() {
+		m := 1.0
+		if d, ok := densities[v.Color]; ok {
+			m = d
+		}
+		c := [3]float64{float64(v.X) + 0.5, float64(v.Y) + 0.5, float64(v.Z) + 0.5}
+		p.Volume++
+		p.Mass += m
+		for i := range 3 {
+			first[i] += m * c[i]
+			for j := range 3 {
+				second[i][j] += m * c[i] * c[j]
+			}
+		}
+	}
+	if p.Mass == 0 {
+		return p
+	}
+	for i := range 3 {
+		p.CenterOfMass[i] = first[i] / p.Mass
+	}
+	// Covariance about the center of mass: Σ m·(c-r)(c-r)ᵀ = Σ m·c·cᵀ - M·r·rᵀ.
+	var cov [3][3]float64
+	for i := range 3 {
+		for j := range 3 {
+			cov[i][j] = second[i][j] - p.Mass*p.CenterOfMass[i]*p.CenterOfMass[j]
+		}
+	}
+	trace := cov[0][0] + cov[1][1] + cov[2][2]
+	for i := range 3 {
+		for j := range 3 {
+			if i == j {
+				// point-mass term plus each unit cube's own inertia (m/6 per axis)
+				p.Inertia[i][j] = trace - cov[i][i] + p.Mass/6
+			} else {
+				p.Inertia[i][j] = -cov[i][j]
+			}
+		}
+	}
+	return p
+}
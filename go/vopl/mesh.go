@@ -3,4 +3,28 @@ package vopl
 type Mesh struct {
 	Vertices []Vertex
 	Indices  []uint32
+	// Lit is set when vertices carry baked light levels (see GenerateLitMesh).
+	Lit bool
+}
+
+// VertexColors returns the RGBA color of every vertex from the palette, darkened by
+// LightBrightness for Lit meshes, and whether any color is translucent.
+func (m *Mesh) VertexColors() ([][4]float32, bool, error) {
+	colors := make([][4]float32, len(m.Vertices))
+	hasAlpha := false
+	for i, v := range m.Vertices {
+		rgba, err := ParseHexColor(Palette[v.Color])
+		if err != nil {
+			return nil, false, err
+		}
+		if m.Lit {
+			b := LightBrightness(v.Light)
+			rgba[0], rgba[1], rgba[2] = rgba[0]*b, rgba[1]*b, rgba[2]*b
+		}
+		colors[i] = rgba
+		if rgba[3] < 1.0 {
+			hasAlpha = true
+		}
+	}
+	return colors, hasAlpha, nil
 }
@@ -0,0 +1,81 @@
+package vopl
+
+import (
+	"math"
+	"math/rand"
+)
+
+// perm is the doubled permutation table for Perlin noise, built from a fixed seed so
+// noise values are stable across runs and platforms.
+var perm [512]int
+
+func init() {
+	r := rand.New(rand.NewSource(0x564F504C)) // "VOPL"
+	p := r.Perm(256)
+	for i := range 512 {
+		perm[i] = p[i&255]
+	}
+}
+
+// Noise3 returns improved Perlin gradient noise at (x,y,z), roughly in [-1,1].
+// It is 0 at integer lattice points, so sample at fractional coordinates (e.g. x*0.1).
+func Noise3(x, y, z float64) float64 {
+	xf, yf, zf := math.Floor(x), math.Floor(y), math.Floor(z)
+	X, Y, Z := int(xf)&255, int(yf)&255, int(zf)&255
+	x, y, z = x-xf, y-yf, z-zf
+	u, v, w := fade(x), fade(y), fade(z)
+	a := perm[X] + Y
+	aa, ab := perm[a]+Z, perm[a+1]+Z
+	b := perm[X+1] + Y
+	ba, bb := perm[b]+Z, perm[b+1]+Z
+	return lerp(w,
+		lerp(v,
+			lerp(u, grad(perm[aa], x, y, z), grad(perm[ba], x-1, y, z)),
+			lerp(u, grad(perm[ab], x, y-1, z), grad(perm[bb], x-1, y-1, z))),
+		lerp(v,
+			lerp(u, grad(perm[aa+1], x, y, z-1), grad(perm[ba+1], x-1, y, z-1)),
+			lerp(u, grad(perm[ab+1], x, y-1, z-1), grad(perm[bb+1], x-1, y-1, z-1))))
+}
+
+// MaxFBMOctaves caps the octaves of FBM3. Beyond it the added detail is far below one
+// voxel, and the doubled coordinates eventually overflow to Inf and NaN.
+const MaxFBMOctaves = 16
+
+// FBM3 sums octaves of Noise3 with halving amplitude and doubling frequency,
+// normalized back to roughly [-1,1]. octaves is clamped to [1, MaxFBMOctaves].
+func FBM3(x, y, z float64, octaves int) float64 {
+	octaves = min(max(octaves, 1), MaxFBMOctaves)
+	sum, amp, norm := 0.0, 1.0, 0.0
+	for range octaves {
+		sum += amp * Noise3(x, y, z)
+		norm += amp
+		amp *= 0.5
+		x, y, z = x*2, y*2, z*2
+	}
+	return sum / norm
+}
+
+func fade(t float64) float64 { return t * t * t * (t*(t*6-15) + 10) }
+
+func lerp(t, a, b float64) float64 { return a + t*(b-a) }
+
+func grad(hash int, x, y, z float64) float64 {
+	h := hash & 15
+	u := y
+	if h < 8 {
+		u = x
+	}
+	v := z
+	if h < 4 {
+		v = y
+	} else if h == 12 || h == 14 {
+		v = x
+	}
+	if h&1 != 0 {
+		u = -u
+	}
+	if h&2 != 0 {
+		v = -v
+	}
+	return u + v
+}
@@ -0,0 +1,225 @@
+package vopl
+
+import (
+	"iter"
+	"math/bits"
+)
+
+// Occupancy is a packed bitset of grid cells: bit z of Occupancy[y][x] stands for cell
+// (x,y,z), mirroring VoxelGrid's [y][x][z] layout. Whole rows along z are tested and
+// combined at once, which is what the mesher and the sparse encoder need.
+type Occupancy [Height][Width]uint16
+
+// Face names one of the six face directions, in the order +x, -x, +y, -y, +z, -z used
+// by the mesher.
+type Face uint8
+
+const (
+	FacePosX Face = iota
+	FaceNegX
+	FacePosY
+	FaceNegY
+	FacePosZ
+	FaceNegZ
+)
+
+// Offset returns the unit step from a cell to its neighbour across face f.
+func (f Face) Offset() [3]int { return faceOffsets[f] }
+
+// Occupancy returns the bitset of g's non-empty cells.
+func (g *VoxelGrid) Occupancy() Occupancy {
+	var o Occupancy
+	for y := range Height {
+		for x := range Width {
+			var row uint16
+			for z, c := range g[y][x] {
+				if c != 0 {
+					row |= 1 << z
+				}
+			}
+			o[y][x] = row
+		}
+	}
+	return o
+}
+
+// Has reports whether cell (x,y,z) is set; cells outside the grid are not.
+func (o Occupancy) Has(x, y, z int) bool {
+	return InBounds(x, y, z) && o[y][x]&(1<<z) != 0
+}
+
+// Set sets or clears cell (x,y,z) and reports whether it was inside the grid.
+func (o *Occupancy) Set(x, y, z int, on bool) bool {
+	if !InBounds(x, y, z) {
+		return false
+	}
+	if on {
+		o[y][x] |= 1 << z
+	} else {
+		o[y][x] &^= 1 << z
+	}
+	return true
+}
+
+// Count returns the number of set cells.
+func (o Occupancy) Count() int {
+	n := 0
+	for y := range Height {
+		for _, row := range o[y] {
+			n += bits.OnesCount16(row)
+		}
+	}
+	return n
+}
+
+// IsEmpty reports whether no cell is set.
+func (o Occupancy) IsEmpty() bool { return o == Occupancy{} }
+
+// And returns the cells set in both o and b.
+func (o Occupancy) And(b Occupancy) Occupancy {
+	return o.combine(b, func(p, q uint16) uint16 { return p & q })
+}
+
+// Or returns the cells set in o or b.
+func (o Occupancy) Or(b Occupancy) Occupancy {
+	return o.combine(b, func(p, q uint16) uint16 { return p | q })
+}
+
+// AndNot returns the cells set in o but not in b.
+func (o Occupancy) AndNot(b Occupancy) Occupancy {
+	return o.combine(b, func(p, q uint16) uint16 { return p &^ q })
+}
+
+// Xor returns the cells set in exactly one of o and b.
+func (o Occupancy) Xor(b Occupancy) Occupancy {
+	return o.combine(b, func(p, q uint16) uint16 { return p ^ q })
+}
+
+// Not returns the cells not set in o.
+func (o Occupancy) Not() Occupancy {
+	return o.combine(Occupancy{}, func(p, _ uint16) uint16 { return ^p })
+}
+
+func (o Occupancy) combine(b Occupancy, op func(p, q uint16) uint16) Occupancy {
+	for y := range Height {
+		for x := range Width {
+			o[y][x] = op(o[y][x], b[y][x])
+		}
+	}
+	return o
+}
+
+// Neighbors returns, for every cell, whether its neighbour across face f is set in o.
+// Neighbours beyond the grid edge read as outside.
+func (o Occupancy) Neighbors(f Face, outside bool) Occupancy {
+	var edge uint16
+	if outside {
+		edge = 0xFFFF
+	}
+	var n Occupancy
+	switch f {
+	case FacePosX, FaceNegX:
+		d := f.Offset()[0]
+		for y := range Height {
+			for x := range Width {
+				if nx := x + d; nx >= 0 && nx < Width {
+					n[y][x] = o[y][nx]
+				} else {
+					n[y][x] = edge
+				}
+			}
+		}
+	case FacePosY, FaceNegY:
+		d := f.Offset()[1]
+		for y := range Height {
+			if ny := y + d; ny >= 0 && ny < Height {
+				n[y] = o[ny]
+			} else {
+				for x := range Width {
+					n[y][x] = edge
+				}
+			}
+		}
+	case FacePosZ:
+		for y := range Height {
+			for x := range Width {
+				n[y][x] = o[y][x]>>1 | edge&(1<<(Depth-1))
+			}
+		}
+	case FaceNegZ:
+		for y := range Height {
+			for x := range Width {
+				n[y][x] = o[y][x]<<1 | edge&1
+			}
+		}
+	}
+	return n
+}
+
+// Exposed returns the set cells whose neighbour across face f is clear or outside the
+// grid: plain face culling, without the cavity check of ExteriorAir.
+func (o Occupancy) Exposed(f Face) Occupancy {
+	return o.AndNot(o.Neighbors(f, false))
+}
+
+// Exterior returns the clear cells reachable from outside the grid through clear
+// cells, face to face (see ExteriorAir). Boundary cells seed the fill, which grows a
+// whole row at a time until it stops changing.
+func (o Occupancy) Exterior() Occupancy {
+	free := o.Not()
+	var air Occupancy
+	for y := range Height {
+		for x := range Width {
+			if x == 0 || y == 0 || x == Width-1 || y == Height-1 {
+				air[y][x] = free[y][x]
+			} else {
+				air[y][x] = free[y][x] & (1 | 1<<(Depth-1))
+			}
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for y := range Height {
+			for x := range Width {
+				grow := air[y][x] | air[y][x]<<1 | air[y][x]>>1
+				if x > 0 {
+					grow |= air[y][x-1]
+				}
+				if x < Width-1 {
+					grow |= air[y][x+1]
+				}
+				if y > 0 {
+					grow |= air[y-1][x]
+				}
+				if y < Height-1 {
+					grow |= air[y+1][x]
+				}
+				grow &= free[y][x]
+				// finish the run along z within this row before moving on
+				for next := (grow | grow<<1 | grow>>1) & free[y][x]; next != grow; next = (grow | grow<<1 | grow>>1) & free[y][x] {
+					grow = next
+				}
+				if grow != air[y][x] {
+					air[y][x] = grow
+					changed = true
+				}
+			}
+		}
+	}
+	return air
+}
+
+// Cells yields the set cells as [x,y,z] in y, x, z order, like VoxelGrid.Occupied.
+func (o Occupancy) Cells() iter.Seq[[3]int] {
+	return func(yield func([3]int) bool) {
+		for y := range Height {
+			for x := range Width {
+				for row := o[y][x]; row != 0; row &= row - 1 {
+					if !yield([3]int{x, y, bits.TrailingZeros16(row)}) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
@@ -97,6 +97,15 @@ const (
 	LayoutRaw PackLayout = 0
 	// LayoutCDC stores a content-defined chunk dictionary and entries as sequences of chunk refs.
 	LayoutCDC PackLayout = 1
+	// LayoutSymmetry stores an entry whose grid is a rotated or mirrored copy of an
+	// earlier entry as a reference to that entry plus a symmetry index.
+	LayoutSymmetry PackLayout = 2
+)
+
+// Entry kinds in the LayoutSymmetry content section.
+const (
+	symEntryRaw = 0 // u32 length + payload
+	symEntryRef = 1 // u32 base entry index + u8 symmetry index
 )
 
 // PackEntry represents a single .vopl payload inside the pack.
@@ -119,7 +128,8 @@ func (p *Pack) Marshal(comp PackCompression) ([]byte, error) {
 }
 
 // MarshalEx encodes the pack into bytes with the specified layout and compression codec.
-// LayoutRaw mirrors v1 semantics; LayoutCDC (v2) builds a chunk dictionary for deduplication across entries.
+// LayoutRaw mirrors v1 semantics; LayoutCDC (v2) builds a chunk dictionary for deduplication across entries;
+// LayoutSymmetry (v2) replaces entries that are one of the 48 cube symmetries of an earlier entry with a reference.
 func (p *Pack) MarshalEx(layout PackLayout, comp PackCompression) ([]byte, error) {
 	if p.Header.Ver != 3 {
 		return nil, fmt.Errorf("apenas VOPL é suportado no pack")
@@ -190,6 +200,28 @@ func (p *Pack) MarshalEx(layout PackLayout, comp PackCompression) ([]byte, error
 				_ = binary.Write(&content, binary.LittleEndian, uint32(idx))
 			}
 		}
+	case LayoutSymmetry:
+		_ = binary.Write(&content, binary.LittleEndian, uint8(LayoutSymmetry))
+		refs := buildSymmetryIndex(p.Header, p.Entries)
+		_ = binary.Write(&content, binary.LittleEndian, uint32(len(p.Entries)))
+		for i, e := range p.Entries {
+			nb := []byte(e.Name)
+			if len(nb) > 0xFFFF {
+				return nil, fmt.Errorf("nome muito longo: %s", e.Name)
+			}
+			_ = binary.Write(&content, binary.LittleEndian, uint16(len(nb)))
+			_, _ = content.Write(nb)
+			_ = binary.Write(&content, binary.LittleEndian, e.Enc)
+			if ref := refs[i]; ref.base >= 0 {
+				_ = binary.Write(&content, binary.LittleEndian, uint8(symEntryRef))
+				_ = binary.Write(&content, binary.LittleEndian, uint32(ref.base))
+				_ = binary.Write(&content, binary.LittleEndian, uint8(ref.sym))
+				continue
+			}
+			_ = binary.Write(&content, binary.LittleEndian, uint8(symEntryRaw))
+			_ = binary.Write(&content, binary.LittleEndian, uint32(len(e.Payload)))
+			_, _ = content.Write(e.Payload)
+		}
 	default:
 		return nil, fmt.Errorf("layout não suportado: %d", layout)
 	}
@@ -231,8 +263,15 @@ func (p *Pack) MarshalEx(layout PackLayout, comp PackCompression) ([]byte, error
 
 // UnmarshalPack parses a .voplpack from bytes and returns the pack structure and compression used.
 func UnmarshalPack(data []byte) (*Pack, PackCompression, error) {
+	pack, _, comp, err := UnmarshalPackEx(data)
+	return pack, comp, err
+}
+
+// UnmarshalPackEx is UnmarshalPack that also returns the layout, so a pack can be
+// re-encoded with MarshalEx the way it was written.
+func UnmarshalPackEx(data []byte) (*Pack, PackLayout, PackCompression, error) {
 	if len(data) < 10 || string(data[:8]) != packMagicStr {
-		return nil, 0, fmt.Errorf("não é um .voplpack válido")
+		return nil, 0, 0, fmt.Errorf("não é um .voplpack válido")
 	}
 	version := data[8]
 	comp := PackCompression(data[9])
@@ -243,48 +282,48 @@ func UnmarshalPack(data []byte) (*Pack, PackCompression, error) {
 	case PackCompZlib:
 		zr, err := zlib.NewReader(bytes.NewReader(contentBytes))
 		if err != nil {
-			return nil, 0, err
+			return nil, 0, 0, err
 		}
 		defer zr.Close()
 		b, err := io.ReadAll(zr)
 		if err != nil {
-			return nil, 0, err
+			return nil, 0, 0, err
 		}
 		contentBytes = b
 	case PackCompZstd:
 		dec, err := zstd.NewReader(nil)
 		if err != nil {
-			return nil, 0, err
+			return nil, 0, 0, err
 		}
 		defer dec.Close()
 		b, err := dec.DecodeAll(contentBytes, nil)
 		if err != nil {
-			return nil, 0, err
+			return nil, 0, 0, err
 		}
 		contentBytes = b
 	default:
-		return nil, 0, fmt.Errorf("tipo de compressão não suportado: %d", comp)
+		return nil, 0, 0, fmt.Errorf("tipo de compressão não suportado: %d", comp)
 	}
 
 	r := bytes.NewReader(contentBytes)
 	var hdr VOPLHeader
 	if err := binary.Read(r, binary.LittleEndian, &hdr.Ver); err != nil {
-		return nil, 0, err
+		return nil, 0, 0, err
 	}
 	if err := binary.Read(r, binary.LittleEndian, &hdr.BPP); err != nil {
-		return nil, 0, err
+		return nil, 0, 0, err
 	}
 	if err := binary.Read(r, binary.LittleEndian, &hdr.W); err != nil {
-		return nil, 0, err
+		return nil, 0, 0, err
 	}
 	if err := binary.Read(r, binary.LittleEndian, &hdr.H); err != nil {
-		return nil, 0, err
+		return nil, 0, 0, err
 	}
 	if err := binary.Read(r, binary.LittleEndian, &hdr.D); err != nil {
-		return nil, 0, err
+		return nil, 0, 0, err
 	}
 	if err := binary.Read(r, binary.LittleEndian, &hdr.Pal); err != nil {
-		return nil, 0, err
+		return nil, 0, 0, err
 	}
 
 	// v1 has no layout byte; v2 includes layout after common header
@@ -292,97 +331,97 @@ func UnmarshalPack(data []byte) (*Pack, PackCompression, error) {
 	if version >= packVersion2 {
 		var lb uint8
 		if err := binary.Read(r, binary.LittleEndian, &lb); err != nil {
-			return nil, 0, err
+			return nil, 0, 0, err
 		}
 		layout = PackLayout(lb)
 	} else if version != packVersion1 {
-		return nil, 0, fmt.Errorf("versão de pack não suportada: %d", version)
+		return nil, 0, 0, fmt.Errorf("versão de pack não suportada: %d", version)
 	}
 
 	switch layout {
 	case LayoutRaw:
 		var n uint32
 		if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
-			return nil, 0, err
+			return nil, 0, 0, err
 		}
 		pack := &Pack{Header: hdr, Entries: make([]PackEntry, n)}
 		for i := uint32(0); i < n; i++ {
 			var nameLen uint16
 			if err := binary.Read(r, binary.LittleEndian, &nameLen); err != nil {
-				return nil, 0, err
+				return nil, 0, 0, err
 			}
 			nameBytes := make([]byte, nameLen)
 			if _, err := io.ReadFull(r, nameBytes); err != nil {
-				return nil, 0, err
+				return nil, 0, 0, err
 			}
 			var enc uint8
 			if err := binary.Read(r, binary.LittleEndian, &enc); err != nil {
-				return nil, 0, err
+				return nil, 0, 0, err
 			}
 			var plen uint32
 			if err := binary.Read(r, binary.LittleEndian, &plen); err != nil {
-				return nil, 0, err
+				return nil, 0, 0, err
 			}
 			payload := make([]byte, plen)
 			if _, err := io.ReadFull(r, payload); err != nil {
-				return nil, 0, err
+				return nil, 0, 0, err
 			}
 			pack.Entries[i] = PackEntry{Name: string(nameBytes), Enc: enc, Payload: payload}
 		}
-		return pack, comp, nil
+		return pack, layout, comp, nil
 	case LayoutCDC:
 		// read CDC params
 		var target, minSz, maxSz uint32
 		if err := binary.Read(r, binary.LittleEndian, &target); err != nil {
-			return nil, 0, err
+			return nil, 0, 0, err
 		}
 		if err := binary.Read(r, binary.LittleEndian, &minSz); err != nil {
-			return nil, 0, err
+			return nil, 0, 0, err
 		}
 		if err := binary.Read(r, binary.LittleEndian, &maxSz); err != nil {
-			return nil, 0, err
+			return nil, 0, 0, err
 		}
 		var nBlocks uint32
 		if err := binary.Read(r, binary.LittleEndian, &nBlocks); err != nil {
-			return nil, 0, err
+			return nil, 0, 0, err
 		}
 		blocks := make([][]byte, nBlocks)
 		for i := uint32(0); i < nBlocks; i++ {
 			var blen uint32
 			if err := binary.Read(r, binary.LittleEndian, &blen); err != nil {
-				return nil, 0, err
+				return nil, 0, 0, err
 			}
 			b := make([]byte, blen)
 			if _, err := io.ReadFull(r, b); err != nil {
-				return nil, 0, err
+				return nil, 0, 0, err
 			}
 			blocks[i] = b
 		}
 		var n uint32
 		if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
-			return nil, 0, err
+			return nil, 0, 0, err
 		}
 		pack := &Pack{Header: hdr, Entries: make([]PackEntry, n)}
 		for i := uint32(0); i < n; i++ {
 			var nameLen uint16
 			if err := binary.Read(r, binary.LittleEndian, &nameLen); err != nil {
-				return nil, 0, err
+				return nil, 0, 0, err
 			}
 			nameBytes := make([]byte, nameLen)
 			if _, err := io.ReadFull(r, nameBytes); err != nil {
-				return nil, 0, err
+				return nil, 0, 0, err
 			}
 			var enc uint8
 			if err := binary.Read(r, binary.LittleEndian, &enc); err != nil {
-				return nil, 0, err
+				return nil, 0, 0, err
 			}
 			var rawLen uint32
 			if err := binary.Read(r, binary.LittleEndian, &rawLen); err != nil {
-				return nil, 0, err
+				return nil, 0, 0, err
 			}
 			var seqLen uint32
 			if err := binary.Read(r, binary.LittleEndian, &seqLen); err != nil {
-				return nil, 0, err
+				return nil, 0, 0, err
 			}
 			// reconstruct payload by concatenating referenced blocks
 			var total uint64
@@ -390,15 +429,15 @@ func UnmarshalPack(data []byte) (*Pack, PackCompression, error) {
 			for j := uint32(0); j < seqLen; j++ {
 				var idx uint32
 				if err := binary.Read(r, binary.LittleEndian, &idx); err != nil {
-					return nil, 0, err
+					return nil, 0, 0, err
 				}
 				if idx >= nBlocks {
-					return nil, 0, fmt.Errorf("índice de bloco inválido: %d", idx)
+					return nil, 0, 0, fmt.Errorf("índice de bloco inválido: %d", idx)
 				}
 				idxs[j] = idx
 				total += uint64(len(blocks[idx]))
 				if total > uint64(rawLen)+uint64(maxSz) { // sanity
-					return nil, 0, fmt.Errorf("tamanho inconsistente em sequência CDC")
+					return nil, 0, 0, fmt.Errorf("tamanho inconsistente em sequência CDC")
 				}
 			}
 			payload := make([]byte, 0, int(total))
@@ -416,9 +455,78 @@ func UnmarshalPack(data []byte) (*Pack, PackCompression, error) {
 		_ = target
 		_ = minSz
 		_ = maxSz // future use/validation
-		return pack, comp, nil
+		return pack, layout, comp, nil
+	case LayoutSymmetry:
+		var n uint32
+		if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+			return nil, 0, 0, err
+		}
+		if uint64(n) > uint64(r.Len()) { // every entry takes at least one byte
+			return nil, 0, 0, fmt.Errorf("número de entradas inválido: %d", n)
+		}
+		pack := &Pack{Header: hdr, Entries: make([]PackEntry, n)}
+		grids := make([]*VoxelGrid, n) // decoded lazily when referenced
+		for i := uint32(0); i < n; i++ {
+			var nameLen uint16
+			if err := binary.Read(r, binary.LittleEndian, &nameLen); err != nil {
+				return nil, 0, 0, err
+			}
+			nameBytes := make([]byte, nameLen)
+			if _, err := io.ReadFull(r, nameBytes); err != nil {
+				return nil, 0, 0, err
+			}
+			var enc, kind uint8
+			if err := binary.Read(r, binary.LittleEndian, &enc); err != nil {
+				return nil, 0, 0, err
+			}
+			if err := binary.Read(r, binary.LittleEndian, &kind); err != nil {
+				return nil, 0, 0, err
+			}
+			e := PackEntry{Name: string(nameBytes), Enc: enc}
+			switch kind {
+			case symEntryRaw:
+				var plen uint32
+				if err := binary.Read(r, binary.LittleEndian, &plen); err != nil {
+					return nil, 0, 0, err
+				}
+				e.Payload = make([]byte, plen)
+				if _, err := io.ReadFull(r, e.Payload); err != nil {
+					return nil, 0, 0, err
+				}
+			case symEntryRef:
+				var base uint32
+				var si uint8
+				if err := binary.Read(r, binary.LittleEndian, &base); err != nil {
+					return nil, 0, 0, err
+				}
+				if err := binary.Read(r, binary.LittleEndian, &si); err != nil {
+					return nil, 0, 0, err
+				}
+				if base >= i {
+					return nil, 0, 0, fmt.Errorf("referência inválida em %s: %d", e.Name, base)
+				}
+				sym, err := SymmetryFromIndex(int(si))
+				if err != nil {
+					return nil, 0, 0, err
+				}
+				if grids[base] == nil {
+					b := pack.Entries[base]
+					if grids[base], err = LoadVoplGridFromBytes(BuildVOPLFromHeaderAndPayload(hdr, b.Enc, b.Payload)); err != nil {
+						return nil, 0, 0, fmt.Errorf("falha ao decodificar %s: %w", b.Name, err)
+					}
+				}
+				grids[i] = grids[base].Transform(sym)
+				if e.Payload, err = encodePayload(grids[i], hdr.BPP, enc); err != nil {
+					return nil, 0, 0, err
+				}
+			default:
+				return nil, 0, 0, fmt.Errorf("tipo de entrada desconhecido: %d", kind)
+			}
+			pack.Entries[i] = e
+		}
+		return pack, layout, comp, nil
 	default:
-		return nil, 0, fmt.Errorf("layout desconhecido: %d", layout)
+		return nil, 0, 0, fmt.Errorf("layout desconhecido: %d", layout)
 	}
 }
 
@@ -502,3 +610,60 @@ func buildCDCIndex(entries []PackEntry, target, minSz, maxSz int) ([][]byte, [][
 	}
 	return blocks, seqs
 }
+
+// symmetryRef points an entry at an earlier raw entry (base, or -1 for none) whose grid
+// becomes the entry's grid under symmetry sym.
+type symmetryRef struct {
+	base int
+	sym  int
+}
+
+// buildSymmetryIndex finds entries whose grid is one of the 48 cube symmetries of an
+// earlier entry (exact duplicates included, as the identity). Grids are bucketed by
+// the smallest hash over all their orientations, which every symmetric copy shares.
+// A reference is only kept when re-encoding the transformed grid with the entry's
+// encoding reproduces its payload byte for byte, so unpacking is lossless; entries
+// that are not 16³ or fail to decode are always stored raw.
+func buildSymmetryIndex(h VOPLHeader, entries []PackEntry) []symmetryRef {
+	refs := make([]symmetryRef, len(entries))
+	for i := range refs {
+		refs[i].base = -1
+	}
+	if int(h.W) != Width || int(h.H) != Height || int(h.D) != Depth {
+		return refs
+	}
+	syms := Symmetries(false)
+	grids := make([]*VoxelGrid, len(entries))
+	buckets := map[uint64][]int{}
+	buf := make([]byte, 0, Width*Height*Depth)
+	for i, e := range entries {
+		g, err := LoadVoplGridFromBytes(BuildVOPLFromHeaderAndPayload(h, e.Enc, e.Payload))
+		if err != nil {
+			continue
+		}
+		grids[i] = g
+		key := uint64(math.MaxUint64)
+		for _, s := range syms {
+			buf = buf[:0]
+			for v := range g.Transform(s).All() {
+				buf = append(buf, v.Color)
+			}
+			key = min(key, xxhash.Sum64(buf))
+		}
+		if payload, err := encodePayload(g, h.BPP, e.Enc); err == nil && bytes.Equal(payload, e.Payload) {
+		candidates:
+			for _, j := range buckets[key] {
+				for _, s := range syms {
+					if *grids[j].Transform(s) == *g {
+						refs[i] = symmetryRef{base: j, sym: s.Index()}
+						break candidates
+					}
+				}
+			}
+		}
+		if refs[i].base < 0 {
+			buckets[key] = append(buckets[key], i)
+		}
+	}
+	return refs
+}
@@ -0,0 +1,282 @@
+package vopl
+
+import "math"
+
+// Raster writes shapes into a grid with a single palette index. Shape coordinates are
+// world voxel coordinates where cell (i,j,k) spans [i,i+1)×[j,j+1)×[k,k+1); Origin is the
+// world position of the grid's cell (0,0,0). Cells falling outside the grid are clipped,
+// so drawing the same shape into neighbouring chunks (each with its own Origin) produces
+// a seamless result. Every method returns the number of cells written into Grid.
+type Raster struct {
+	Grid   *VoxelGrid
+	Origin [3]int
+	Color  uint8
+}
+
+// Implicit reports whether the point (x,y,z), in world voxel units, lies inside a shape.
+type Implicit func(x, y, z float64) bool
+
+// Fill writes every cell of the world box [lo,hi) whose center lies inside shape.
+// When hollow is true only boundary cells are written: inside cells with at least one
+// face neighbour outside the shape. The test is evaluated in world space so shells stay
+// closed across chunk borders.
+func (r Raster) Fill(lo, hi [3]int, shape Implicit, hollow bool) int {
+	lo, hi = r.clip(lo, hi)
+	inside := func(wx, wy, wz int) bool {
+		return shape(float64(wx)+0.5, float64(wy)+0.5, float64(wz)+0.5)
+	}
+	n := 0
+	for wy := lo[1]; wy < hi[1]; wy++ {
+		for wx := lo[0]; wx < hi[0]; wx++ {
+			for wz := lo[2]; wz < hi[2]; wz++ {
+				if !inside(wx, wy, wz) {
+					continue
+				}
+				if hollow {
+					boundary := false
+					for _, d := range faceOffsets {
+						if !inside(wx+d[0], wy+d[1], wz+d[2]) {
+							boundary = true
+							break
+						}
+					}
+					if !boundary {
+						continue
+					}
+				}
+				r.Grid[wy-r.Origin[1]][wx-r.Origin[0]][wz-r.Origin[2]] = r.Color
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// clip intersects the world box [lo,hi) with the grid's world extent.
+func (r Raster) clip(lo, hi [3]int) ([3]int, [3]int) {
+	dims := [3]int{Width, Height, Depth}
+	for i := range 3 {
+		lo[i] = max(lo[i], r.Origin[i])
+		hi[i] = max(min(hi[i], r.Origin[i]+dims[i]), lo[i])
+	}
+	return lo, hi
+}
+
+// set writes one world cell, clipping to the grid.
+func (r Raster) set(wx, wy, wz int) int {
+	if r.Grid.Set(wx-r.Origin[0], wy-r.Origin[1], wz-r.Origin[2], r.Color) {
+		return 1
+	}
+	return 0
+}
+
+// floatBounds returns the integer cell box covering [fmin,fmax] in world units.
+func floatBounds(fmin, fmax [3]float64) (lo, hi [3]int) {
+	for i := range 3 {
+		lo[i] = int(math.Floor(fmin[i]))
+		hi[i] = int(math.Ceil(fmax[i])) + 1
+	}
+	return
+}
+
+// Box fills the world box [lo,hi).
+func (r Raster) Box(lo, hi [3]int, hollow bool) int {
+	for i := range 3 {
+		if hi[i] < lo[i] {
+			lo[i], hi[i] = hi[i], lo[i]
+		}
+	}
+	inside := func(x, y, z float64) bool {
+		return x > float64(lo[0]) && x < float64(hi[0]) &&
+			y > float64(lo[1]) && y < float64(hi[1]) &&
+			z > float64(lo[2]) && z < float64(hi[2])
+	}
+	return r.Fill(lo, hi, inside, hollow)
+}
+
+// Sphere fills cells whose centers lie within radius of center.
+func (r Raster) Sphere(center [3]float64, radius float64, hollow bool) int {
+	return r.Ellipsoid(center, [3]float64{radius, radius, radius}, hollow)
+}
+
+// Ellipsoid fills cells whose centers lie inside the axis-aligned ellipsoid with the given radii.
+func (r Raster) Ellipsoid(center, radii [3]float64, hollow bool) int {
+	if radii[0] <= 0 || radii[1] <= 0 || radii[2] <= 0 {
+		return 0
+	}
+	inside := func(x, y, z float64) bool {
+		dx := (x - center[0]) / radii[0]
+		dy := (y - center[1]) / radii[1]
+		dz := (z - center[2]) / radii[2]
+		return dx*dx+dy*dy+dz*dz <= 1
+	}
+	lo, hi := floatBounds(
+		[3]float64{center[0] - radii[0], center[1] - radii[1], center[2] - radii[2]},
+		[3]float64{center[0] + radii[0], center[1] + radii[1], center[2] + radii[2]},
+	)
+	return r.Fill(lo, hi, inside, hollow)
+}
+
+// Cylinder fills a right circular cylinder along axis. base is the center of the bottom
+// cap; the cylinder extends height units towards +axis.
+func (r Raster) Cylinder(base [3]float64, axis Axis, radius, height float64, hollow bool) int {
+	return r.taper(base, axis, radius, radius, height, hollow)
+}
+
+// Cone fills a right circular cone along axis with its base disc (of the given radius)
+// centered at base and its apex height units towards +axis.
+func (r Raster) Cone(base [3]float64, axis Axis, radius, height float64, hollow bool) int {
+	return r.taper(base, axis, radius, 0, height, hollow)
+}
+
+// taper fills a frustum whose radius goes linearly from r0 at base to r1 at base+height.
+func (r Raster) taper(base [3]float64, axis Axis, r0, r1, height float64, hollow bool) int {
+	if height <= 0 || (r0 <= 0 && r1 <= 0) {
+		return 0
+	}
+	a := int(axis)
+	u, v := (a+1)%3, (a+2)%3
+	inside := func(x, y, z float64) bool {
+		p := [3]float64{x, y, z}
+		t := (p[a] - base[a]) / height
+		if t < 0 || t > 1 {
+			return false
+		}
+		rad := r0 + (r1-r0)*t
+		du, dv := p[u]-base[u], p[v]-base[v]
+		return du*du+dv*dv <= rad*rad
+	}
+	rmax := math.Max(r0, r1)
+	var lo, hi [3]float64
+	lo[a], hi[a] = base[a], base[a]+height
+	lo[u], hi[u] = base[u]-rmax, base[u]+rmax
+	lo[v], hi[v] = base[v]-rmax, base[v]+rmax
+	ilo, ihi := floatBounds(lo, hi)
+	return r.Fill(ilo, ihi, inside, hollow)
+}
+
+// Line draws a 26-connected 3D Bresenham line between the world cells a and b, inclusive.
+func (r Raster) Line(a, b [3]int) int {
+	d := [3]int{abs(b[0] - a[0]), abs(b[1] - a[1]), abs(b[2] - a[2])}
+	s := [3]int{sign(b[0] - a[0]), sign(b[1] - a[1]), sign(b[2] - a[2])}
+	// drive along the dominant axis
+	m := 0
+	if d[1] > d[m] {
+		m = 1
+	}
+	if d[2] > d[m] {
+		m = 2
+	}
+	o1, o2 := (m+1)%3, (m+2)%3
+	p := a
+	e1 := 2*d[o1] - d[m]
+	e2 := 2*d[o2] - d[m]
+	n := r.set(p[0], p[1], p[2])
+	for range d[m] {
+		if e1 > 0 {
+			p[o1] += s[o1]
+			e1 -= 2 * d[m]
+		}
+		if e2 > 0 {
+			p[o2] += s[o2]
+			e2 -= 2 * d[m]
+		}
+		e1 += 2 * d[o1]
+		e2 += 2 * d[o2]
+		p[m] += s[m]
+		n += r.set(p[0], p[1], p[2])
+	}
+	return n
+}
+
+func sign(v int) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return 0
+}
+
+// Triangle writes the cells touched by the triangle abc (world units). When hollow is
+// true only its three edges are drawn, as lines between the cells containing the vertices.
+func (r Raster) Triangle(a, b, c [3]float64, hollow bool) int {
+	if hollow {
+		ca, cb, cc := floorCell(a), floorCell(b), floorCell(c)
+		return r.Line(ca, cb) + r.Line(cb, cc) + r.Line(cc, ca)
+	}
+	var mn, mx [3]float64
+	for i := range 3 {
+		mn[i] = math.Min(a[i], math.Min(b[i], c[i]))
+		mx[i] = math.Max(a[i], math.Max(b[i], c[i]))
+	}
+	lo, hi := floatBounds(mn, mx)
+	lo, hi = r.clip(lo, hi)
+	n := 0
+	for wy := lo[1]; wy < hi[1]; wy++ {
+		for wx := lo[0]; wx < hi[0]; wx++ {
+			for wz := lo[2]; wz < hi[2]; wz++ {
+				center := [3]float64{float64(wx) + 0.5, float64(wy) + 0.5, float64(wz) + 0.5}
+				if triangleOverlapsCell(center, a, b, c) {
+					n += r.set(wx, wy, wz)
+				}
+			}
+		}
+	}
+	return n
+}
+
+func floorCell(p [3]float64) [3]int {
+	return [3]int{int(math.Floor(p[0])), int(math.Floor(p[1])), int(math.Floor(p[2]))}
+}
+
+// triangleOverlapsCell is the separating-axis triangle/box test (Akenine-Möller)
+// for the unit cell centered at center.
+func triangleOverlapsCell(center, a, b, c [3]float64) bool {
+	const h = 0.5
+	v0, v1, v2 := sub3(a, center), sub3(b, center), sub3(c, center)
+	edges := [3][3]float64{sub3(v1, v0), sub3(v2, v1), sub3(v0, v2)}
+	verts := [3][3]float64{v0, v1, v2}
+	project := func(axis [3]float64) bool {
+		r := h * (math.Abs(axis[0]) + math.Abs(axis[1]) + math.Abs(axis[2]))
+		mn, mx := math.Inf(1), math.Inf(-1)
+		for _, v := range verts {
+			p := dot3(v, axis)
+			mn = math.Min(mn, p)
+			mx = math.Max(mx, p)
+		}
+		return !(mn > r || mx < -r)
+	}
+	// 9 edge cross-axis tests
+	for _, e := range edges {
+		for i := range 3 {
+			var unit [3]float64
+			unit[i] = 1
+			if ax := cross3(unit, e); ax != ([3]float64{}) && !project(ax) {
+				return false
+			}
+		}
+	}
+	// box face normals
+	for i := range 3 {
+		var unit [3]float64
+		unit[i] = 1
+		if !project(unit) {
+			return false
+		}
+	}
+	// triangle normal
+	if n := cross3(edges[0], edges[1]); n != ([3]float64{}) && !project(n) {
+		return false
+	}
+	return true
+}
+
+func sub3(a, b [3]float64) [3]float64 { return [3]float64{a[0] - b[0], a[1] - b[1], a[2] - b[2]} }
+
+func dot3(a, b [3]float64) float64 { return a[0]*b[0] + a[1]*b[1] + a[2]*b[2] }
+
+func cross3(a, b [3]float64) [3]float64 {
+	return [3]float64{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+}
@@ -0,0 +1,196 @@
+package vopl
+
+import (
+	"fmt"
+	"math"
+)
+
+// Voxels answers point lookups; positions outside the data read as 0 (empty).
+// *VoxelGrid uses local chunk coordinates; ChunkMap (see chunkid.go) and *World use
+// world coordinates.
+type Voxels interface {
+	Get(x, y, z int) uint8
+}
+
+// RayHit describes the first solid voxel met by a ray.
+type RayHit struct {
+	// Voxel is the cell that was hit, in the coordinates of the queried Voxels.
+	Voxel [3]int
+	// Normal is the outward normal of the face that was entered ([0,0,0] when the ray starts inside Voxel).
+	Normal [3]int
+	// Distance is the distance from the ray origin to the entry point.
+	Distance float64
+	// Color is the palette index of Voxel.
+	Color uint8
+}
+
+// Raycast walks the cells crossed by the ray origin + t*dir (Amanatides & Woo DDA) and
+// returns the first non-empty one within maxDist. dir need not be normalized; Distance
+// is measured in voxel units along the normalized direction. Cell (i,j,k) spans
+// [i,i+1)×[j,j+1)×[k,k+1).
+func Raycast(src Voxels, origin, dir [3]float64, maxDist float64) (RayHit, bool, error) {
+	length := math.Sqrt(dot3(dir, dir))
+	if length == 0 || math.IsNaN(length) {
+		return RayHit{}, false, fmt.Errorf("ray direction must be non-zero")
+	}
+	if !(maxDist > 0) || math.IsInf(maxDist, 0) {
+		return RayHit{}, false, fmt.Errorf("maxDist must be positive and finite")
+	}
+	d := [3]float64{dir[0] / length, dir[1] / length, dir[2] / length}
+	cell := floorCell(origin)
+	var step [3]int
+	var tMax, tDelta [3]float64
+	for i := range 3 {
+		switch {
+		case d[i] > 0:
+			step[i] = 1
+			tDelta[i] = 1 / d[i]
+			tMax[i] = (float64(cell[i]+1) - origin[i]) / d[i]
+		case d[i] < 0:
+			step[i] = -1
+			tDelta[i] = -1 / d[i]
+			tMax[i] = (float64(cell[i]) - origin[i]) / d[i]
+		default:
+			tDelta[i] = math.Inf(1)
+			tMax[i] = math.Inf(1)
+		}
+	}
+	var normal [3]int
+	t := 0.0
+	for t <= maxDist {
+		if c := src.Get(cell[0], cell[1], cell[2]); c != 0 {
+			return RayHit{Voxel: cell, Normal: normal, Distance: t, Color: c}, true, nil
+		}
+		// advance across the nearest cell boundary
+		a := 0
+		if tMax[1] < tMax[a] {
+			a = 1
+		}
+		if tMax[2] < tMax[a] {
+			a = 2
+		}
+		t = tMax[a]
+		tMax[a] += tDelta[a]
+		cell[a] += step[a]
+		normal = [3]int{}
+		normal[a] = -step[a]
+	}
+	return RayHit{}, false, nil
+}
+
+// AABB is an axis-aligned box in world (or local) voxel units.
+type AABB struct {
+	Min [3]float64
+	Max [3]float64
+}
+
+// cellRange returns the cells whose volume can intersect b.
+func (b AABB) cellRange() (lo, hi [3]int) {
+	for i := range 3 {
+		lo[i] = int(math.Floor(b.Min[i]))
+		hi[i] = int(math.Ceil(b.Max[i]))
+	}
+	return
+}
+
+// OverlappingVoxels returns the solid cells whose volume overlaps b with positive volume
+// (touching faces do not count), scanning y, then x, then z.
+func OverlappingVoxels(src Voxels, b AABB) [][3]int {
+	lo, hi := b.cellRange()
+	var out [][3]int
+	for y := lo[1]; y < hi[1]; y++ {
+		for x := lo[0]; x < hi[0]; x++ {
+			for z := lo[2]; z < hi[2]; z++ {
+				if src.Get(x, y, z) != 0 {
+					out = append(out, [3]int{x, y, z})
+				}
+			}
+		}
+	}
+	return out
+}
+
+// Overlaps reports whether b overlaps any solid cell.
+func Overlaps(src Voxels, b AABB) bool {
+	lo, hi := b.cellRange()
+	for y := lo[1]; y < hi[1]; y++ {
+		for x := lo[0]; x < hi[0]; x++ {
+			for z := lo[2]; z < hi[2]; z++ {
+				if src.Get(x, y, z) != 0 {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// SweepHit describes the first contact of a box moving through solid cells.
+type SweepHit struct {
+	// Time is the fraction of the motion travelled before contact, in [0,1].
+	Time float64
+	// Voxel is the cell that stopped the box.
+	Voxel [3]int
+	// Normal is the contact face normal ([0,0,0] when the box starts overlapping Voxel).
+	Normal [3]int
+}
+
+// SweepAABB moves b by motion and returns the earliest contact with a solid cell.
+// A box that already overlaps a solid cell reports a hit at Time 0.
+func SweepAABB(src Voxels, b AABB, motion [3]float64) (SweepHit, bool) {
+	var swept AABB
+	for i := range 3 {
+		swept.Min[i] = math.Min(b.Min[i], b.Min[i]+motion[i])
+		swept.Max[i] = math.Max(b.Max[i], b.Max[i]+motion[i])
+	}
+	best := SweepHit{Time: math.Inf(1)}
+	found := false
+	for _, cell := range OverlappingVoxels(src, swept) {
+		t, n, ok := sweepCell(b, motion, cell)
+		if ok && t < best.Time {
+			best = SweepHit{Time: t, Voxel: cell, Normal: n}
+			found = true
+		}
+	}
+	return best, found
+}
+
+// sweepCell computes the entry time of box b moving by motion into the unit cell.
+func sweepCell(b AABB, motion [3]float64, cell [3]int) (float64, [3]int, bool) {
+	entry, exit := math.Inf(-1), math.Inf(1)
+	axis := -1
+	for i := range 3 {
+		cmin, cmax := float64(cell[i]), float64(cell[i]+1)
+		var t0, t1 float64
+		if motion[i] == 0 {
+			if b.Max[i] <= cmin || b.Min[i] >= cmax {
+				return 0, [3]int{}, false
+			}
+			t0, t1 = math.Inf(-1), math.Inf(1)
+		} else {
+			t0 = (cmin - b.Max[i]) / motion[i]
+			t1 = (cmax - b.Min[i]) / motion[i]
+			if t0 > t1 {
+				t0, t1 = t1, t0
+			}
+		}
+		if t0 > entry {
+			entry = t0
+			axis = i
+		}
+		exit = math.Min(exit, t1)
+	}
+	if entry >= exit || entry > 1 || exit <= 0 {
+		return 0, [3]int{}, false
+	}
+	var n [3]int
+	if entry < 0 || axis < 0 {
+		return 0, n, true // already overlapping
+	}
+	if motion[axis] > 0 {
+		n[axis] = -1
+	} else {
+		n[axis] = 1
+	}
+	return entry, n, true
+}
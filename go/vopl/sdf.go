@@ -0,0 +1,210 @@
+package vopl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// SDF is a signed distance field sampled over a grid. Samples sit at the centers of a
+// Size³ lattice covering the 16³ chunk, Supersample samples per voxel along each axis.
+// Values are in voxel units: negative inside occupied voxels, positive outside, and
+// half a sample in magnitude next to a voxel face. Data is ordered x fastest, then y,
+// then z (the usual 3D texture layout).
+type SDF struct {
+	Size        int
+	Supersample int
+	Data        []float32
+}
+
+// At returns the sample at lattice position (x,y,z).
+func (s *SDF) At(x, y, z int) float32 {
+	return s.Data[x+y*s.Size+z*s.Size*s.Size]
+}
+
+// ComputeSDF builds an exact Euclidean signed distance field of g with the given
+// supersampling factor (1 = one sample per voxel): each sample holds the distance from
+// its center to the nearest face of the opposite region, treating every sample as a
+// cube of side 1/supersample. Space outside the chunk is treated as empty. For a grid
+// with no occupied cells the distances are capped at the chunk diagonal.
+func ComputeSDF(g *VoxelGrid, supersample int) (*SDF, error) {
+	if supersample < 1 || supersample > 16 {
+		return nil, fmt.Errorf("supersample must be in [1,16] (got %d)", supersample)
+	}
+	n := Width * supersample
+	if Height != Width || Depth != Width {
+		return nil, fmt.Errorf("SDF requires a cubic grid")
+	}
+	total := n * n * n
+	inside := make([]bool, total)
+	for z := range n {
+		for y := range n {
+			for x := range n {
+				inside[x+y*n+z*n*n] = g[y/supersample][x/supersample][z/supersample] != 0
+			}
+		}
+	}
+	toInside := squaredCellDT(inside, n, true)
+	toOutside := squaredCellDT(inside, n, false)
+	h := 1 / float64(supersample)
+	limit := math.Sqrt(3) * float64(Width)
+	data := make([]float32, total)
+	for i := range data {
+		var d float64
+		if inside[i] {
+			// the nearest empty sample may lie just beyond the chunk edge
+			x, y, z := i%n, (i/n)%n, i/(n*n)
+			edge := min(x+1, n-x, y+1, n-y, z+1, n-z)
+			out := math.Min(toOutside[i], cellCost(edge))
+			d = -math.Sqrt(out) * h
+		} else {
+			d = math.Sqrt(toInside[i]) * h
+		}
+		d = math.Max(-limit, math.Min(limit, d))
+		data[i] = float32(d)
+	}
+	return &SDF{Size: n, Supersample: supersample, Data: data}, nil
+}
+
+// cellCost is the squared distance, in samples, along one axis from a sample center to
+// the nearest face of the sample cell d samples away.
+func cellCost(d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	a := math.Abs(float64(d)) - 0.5
+	return a * a
+}
+
+// squaredCellDT returns, for every sample, the squared lattice distance from its center
+// to the nearest cell whose mask value equals target. The distance splits into a sum
+// of per-axis cellCost terms, so it is computed one axis at a time like the
+// Felzenszwalb & Huttenlocher transform.
+func squaredCellDT(mask []bool, n int, target bool) []float64 {
+	inf := math.Inf(1)
+	f := make([]float64, len(mask))
+	for i, m := range mask {
+		if m == target {
+			f[i] = 0
+		} else {
+			f[i] = inf
+		}
+	}
+	line := make([]float64, n)
+	out := make([]float64, n)
+	v := make([]int, n)
+	t := make([]int, n)
+	strides := [3]int{1, n, n * n}
+	for axis := range 3 {
+		stride := strides[axis]
+		o1, o2 := strides[(axis+1)%3], strides[(axis+2)%3]
+		for a := range n {
+			for b := range n {
+				base := a*o1 + b*o2
+				for i := range n {
+					line[i] = f[base+i*stride]
+				}
+				cellDT1D(line, out, v, t)
+				for i := range n {
+					f[base+i*stride] = out[i]
+				}
+			}
+		}
+	}
+	return f
+}
+
+// cellDT1D computes d[x] = min over q of f[q] + cellCost(x-q) as a lower envelope
+// (Meijster et al.). cellCost is convex, so two of its translates cross at most once
+// and each crossing is found by binary search. Samples with f=+Inf are not sites; if
+// there are none the output is all +Inf.
+func cellDT1D(f, d []float64, v, t []int) {
+	n := len(f)
+	at := func(q, x int) float64 { return f[q] + cellCost(x-q) }
+	k := -1
+	for u := range n {
+		if math.IsInf(f[u], 1) {
+			continue
+		}
+		for k >= 0 && at(v[k], t[k]) > at(u, t[k]) {
+			k--
+		}
+		if k < 0 {
+			k = 0
+			v[0] = u
+			t[0] = 0
+			continue
+		}
+		// v[k] is no worse than u at t[k]; find the first x where u wins
+		lo, hi := t[k], n
+		for hi-lo > 1 {
+			mid := (lo + hi) / 2
+			if at(v[k], mid) <= at(u, mid) {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		if hi < n {
+			k++
+			v[k] = u
+			t[k] = hi
+		}
+	}
+	if k < 0 {
+		for x := range n {
+			d[x] = math.Inf(1)
+		}
+		return
+	}
+	for x := n - 1; x >= 0; x-- {
+		d[x] = at(v[k], x)
+		if x == t[k] {
+			k--
+		}
+	}
+}
+
+// WriteRaw writes Data as little-endian float32 values in x, y, z order with no header.
+func (s *SDF) WriteRaw(w io.Writer) error {
+	return binary.Write(w, binary.LittleEndian, s.Data)
+}
+
+// KTX 1.1 constants for a single-channel float 3D texture.
+const (
+	glFloat = 0x1406
+	glRed   = 0x1903
+	glR32F  = 0x822E
+)
+
+var ktxIdentifier = [12]byte{0xAB, 'K', 'T', 'X', ' ', '1', '1', 0xBB, '\r', '\n', 0x1A, '\n'}
+
+// WriteKTX writes the field as a KTX 1.1 3D texture (GL_R32F, one mip level).
+func (s *SDF) WriteKTX(w io.Writer) error {
+	var buf bytes.Buffer
+	buf.Write(ktxIdentifier[:])
+	hdr := []uint32{
+		0x04030201,     // endianness
+		glFloat,        // glType
+		4,              // glTypeSize
+		glRed,          // glFormat
+		glR32F,         // glInternalFormat
+		glRed,          // glBaseInternalFormat
+		uint32(s.Size), // pixelWidth
+		uint32(s.Size), // pixelHeight
+		uint32(s.Size), // pixelDepth
+		0,              // numberOfArrayElements
+		1,              // numberOfFaces
+		1,              // numberOfMipmapLevels
+		0,              // bytesOfKeyValueData
+		uint32(len(s.Data) * 4),
+	}
+	_ = binary.Write(&buf, binary.LittleEndian, hdr)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	// rows are Size*4 bytes, already 4-byte aligned
+	return s.WriteRaw(w)
+}
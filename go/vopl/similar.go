@@ -0,0 +1,233 @@
+package vopl
+
+import (
+	"encoding/binary"
+	"math"
+	"slices"
+	"sort"
+
+	xxhash "github.com/cespare/xxhash/v2"
+)
+
+// FingerprintSize is the number of MinHash slots in a Fingerprint.
+const FingerprintSize = 64
+
+// Fingerprint is a MinHash signature of a grid's occupied (cell, color) pairs. The
+// fraction of equal slots between two fingerprints estimates their Similarity, so
+// grids that differ by a few voxels get nearly identical fingerprints.
+type Fingerprint [FingerprintSize]uint32
+
+// ComputeFingerprint returns the fingerprint of g. Every slot of an empty grid is
+// math.MaxUint32.
+func ComputeFingerprint(g *VoxelGrid) Fingerprint {
+	var f Fingerprint
+	for i := range f {
+		f[i] = math.MaxUint32
+	}
+	for v := range g.Occupied() {
+		feature := uint64(LinearIndex(v.X, v.Y, v.Z))<<8 | uint64(v.Color)
+		for i := range f {
+			if h := uint32(mix64(feature^uint64(i)*0x9E3779B97F4A7C15) >> 32); h < f[i] {
+				f[i] = h
+			}
+		}
+	}
+	return f
+}
+
+// mix64 is the SplitMix64 finalizer.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xBF58476D1CE4E5B9
+	x ^= x >> 27
+	x *= 0x94D049BB133111EB
+	return x ^ x>>31
+}
+
+// Estimate returns the fraction of slots f shares with o, an estimate of Similarity.
+func (f Fingerprint) Estimate(o Fingerprint) float64 {
+	same := 0
+	for i := range f {
+		if f[i] == o[i] {
+			same++
+		}
+	}
+	return float64(same) / FingerprintSize
+}
+
+// bandKeys splits f into bands of rows slots each and returns one bucket key per band.
+func (f Fingerprint) bandKeys(rows int) []string {
+	keys := make([]string, 0, FingerprintSize/rows)
+	buf := make([]byte, 1+4*rows)
+	for b := 0; b < FingerprintSize; b += rows {
+		buf[0] = byte(b / rows)
+		for r := range rows {
+			binary.LittleEndian.PutUint32(buf[1+4*r:], f[b+r])
+		}
+		keys = append(keys, string(buf))
+	}
+	return keys
+}
+
+// Similarity is the Jaccard index of the occupied (cell, color) pairs of a and b:
+// cells holding the same color in both, over cells occupied in either (a cell whose
+// color differs counts twice in the union). Two empty grids have similarity 1.
+func Similarity(a, b *VoxelGrid) float64 {
+	same, union := 0, 0
+	for y := range Height {
+		for x := range Width {
+			for z := range Depth {
+				ca, cb := a[y][x][z], b[y][x][z]
+				switch {
+				case ca == 0 && cb == 0:
+				case ca == cb:
+					same++
+					union++
+				case ca != 0 && cb != 0:
+					union += 2
+				default:
+					union++
+				}
+			}
+		}
+	}
+	if union == 0 {
+		return 1
+	}
+	return float64(same) / float64(union)
+}
+
+// SimilarityCluster is a group of grids linked by pairwise similarity.
+type SimilarityCluster struct {
+	// Members are indices into the input slice, ascending.
+	Members []int
+	// MinSimilarity is the weakest link of the cluster: the lowest Similarity along a
+	// maximum spanning tree of its similar pairs.
+	MinSimilarity float64
+}
+
+// ClusterBySimilarity groups grids whose Similarity is at least threshold, with single
+// linkage (a chain of similar pairs forms one cluster). Candidate pairs come from
+// locality-sensitive hashing of the fingerprints, with bands sized so a pair at the
+// threshold is found with at least 99% probability. Below a threshold of about 0.07
+// even one-row bands fall short of that (about 96% at 0.05), so such pairs may be
+// missed. Candidates are then checked exactly. Byte-identical grids are linked with
+// similarity 1 up front and only the first of them is fingerprinted, so duplicates
+// cost no pairwise checks. Only clusters of two or more grids are returned, ordered by
+// first member.
+func ClusterBySimilarity(grids []*VoxelGrid, threshold float64) []SimilarityCluster {
+	threshold = math.Min(math.Max(threshold, 0), 1)
+	type edge struct {
+		a, b int
+		sim  float64
+	}
+	var edges []edge
+
+	// reps holds the first of each set of identical grids, ascending
+	var reps []int
+	byContent := map[uint64][]int{}
+	buf := make([]byte, 0, Width*Height*Depth)
+next:
+	for i, g := range grids {
+		buf = buf[:0]
+		for v := range g.All() {
+			buf = append(buf, v.Color)
+		}
+		key := xxhash.Sum64(buf)
+		for _, r := range byContent[key] {
+			if *grids[r] == *g {
+				edges = append(edges, edge{r, i, 1})
+				continue next
+			}
+		}
+		byContent[key] = append(byContent[key], i)
+		reps = append(reps, i)
+	}
+
+	rows := lshRows(threshold)
+	buckets := map[string][]int{}
+	for _, i := range reps {
+		for _, k := range ComputeFingerprint(grids[i]).bandKeys(rows) {
+			buckets[k] = append(buckets[k], i)
+		}
+	}
+	// a pair sharing several bands is listed once per band; sort and dedupe before
+	// the exact check
+	var pairs [][2]int
+	for _, members := range buckets {
+		for x := 0; x < len(members); x++ {
+			for y := x + 1; y < len(members); y++ {
+				pairs = append(pairs, [2]int{members[x], members[y]})
+			}
+		}
+	}
+	slices.SortFunc(pairs, func(p, q [2]int) int { return slices.Compare(p[:], q[:]) })
+	for _, pair := range slices.Compact(pairs) {
+		if s := Similarity(grids[pair[0]], grids[pair[1]]); s >= threshold {
+			edges = append(edges, edge{pair[0], pair[1], s})
+		}
+	}
+
+	parent := make([]int, len(grids))
+	minSim := make([]float64, len(grids))
+	for i := range parent {
+		parent[i] = i
+		minSim[i] = 1
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	// Merging the most similar pairs first (Kruskal) makes MinSimilarity the weakest
+	// link of a maximum spanning tree, independent of bucket order.
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].sim != edges[j].sim {
+			return edges[i].sim > edges[j].sim
+		}
+		if edges[i].a != edges[j].a {
+			return edges[i].a < edges[j].a
+		}
+		return edges[i].b < edges[j].b
+	})
+	for _, e := range edges {
+		ra, rb := find(e.a), find(e.b)
+		if ra == rb {
+			continue
+		}
+		if rb < ra {
+			ra, rb = rb, ra
+		}
+		parent[rb] = ra
+		minSim[ra] = math.Min(e.sim, math.Min(minSim[ra], minSim[rb]))
+	}
+
+	groups := map[int][]int{}
+	for i := range grids {
+		r := find(i)
+		groups[r] = append(groups[r], i)
+	}
+	var out []SimilarityCluster
+	for r, members := range groups {
+		if len(members) > 1 {
+			out = append(out, SimilarityCluster{Members: members, MinSimilarity: minSim[r]})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Members[0] < out[j].Members[0] })
+	return out
+}
+
+// lshRows picks the widest band (fewest false candidates) that still reports a pair of
+// the given similarity with probability >= 0.99, falling back to one row per band,
+// the most sensitive split, when none does.
+func lshRows(threshold float64) int {
+	for _, rows := range []int{16, 8, 4, 2} {
+		bands := float64(FingerprintSize / rows)
+		if 1-math.Pow(1-math.Pow(threshold, float64(rows)), bands) >= 0.99 {
+			return rows
+		}
+	}
+	return 1
+}
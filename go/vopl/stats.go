@@ -0,0 +1,61 @@
+package vopl
+
+// GridStats summarizes the contents of a single grid.
+type GridStats struct {
+	// VoxelCount is the number of occupied cells.
+	VoxelCount int `json:"voxelCount"`
+	// Bounds is the occupied bounding box; zero when the grid is empty.
+	Bounds Box `json:"bounds"`
+	// Histogram counts occupied cells per palette index.
+	Histogram map[uint8]int `json:"histogram"`
+	// ExposedFaces counts voxel faces adjacent to exterior air or the grid edge.
+	ExposedFaces int `json:"exposedFaces"`
+	// QuadCount is the number of quads emitted by GenerateMesh.
+	QuadCount int `json:"quadCount"`
+}
+
+// Bounds returns the bounding box of the occupied cells and false when the grid is empty.
+func (g *VoxelGrid) Bounds() (Box, bool) {
+	var b Box
+	found := false
+	for v := range g.Occupied() {
+		if !found {
+			b = cellBox(v.X, v.Y, v.Z)
+			found = true
+			continue
+		}
+		b.extend(v.X, v.Y, v.Z)
+	}
+	return b, found
+}
+
+// ExposedFaces counts faces of occupied cells that GenerateMesh would emit,
+// i.e. faces whose neighbour is outside the grid or exterior air.
+func (g *VoxelGrid) ExposedFaces() int {
+	air := ExteriorAir(g)
+	n := 0
+	for v := range g.Occupied() {
+		for _, d := range faceOffsets {
+			if air.visibleFrom(v.X+d[0], v.Y+d[1], v.Z+d[2]) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// faceOffsets are the six face-neighbour deltas as [x,y,z].
+var faceOffsets = [6][3]int{{1, 0, 0}, {-1, 0, 0}, {0, 1, 0}, {0, -1, 0}, {0, 0, 1}, {0, 0, -1}}
+
+// Stats computes GridStats for g. It runs the greedy mesher to report QuadCount.
+func Stats(g *VoxelGrid) GridStats {
+	s := GridStats{Histogram: map[uint8]int{}}
+	for v := range g.Occupied() {
+		s.VoxelCount++
+		s.Histogram[v.Color]++
+	}
+	s.Bounds, _ = g.Bounds()
+	s.ExposedFaces = g.ExposedFaces()
+	s.QuadCount = len(GenerateMesh(g).Indices) / 6
+	return s
+}
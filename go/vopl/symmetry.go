@@ -0,0 +1,117 @@
+package vopl
+
+import "fmt"
+
+// Symmetry is one of the 48 symmetries of a box's axes: an axis permutation followed
+// by optional reflections. Output axis i takes input axis Perm[i], reversed when Flip[i].
+type Symmetry struct {
+	Perm [3]uint8
+	Flip [3]bool
+}
+
+// axisPerms lists the six axis permutations; the first is the identity and the first
+// three are even.
+var axisPerms = [6][3]uint8{{0, 1, 2}, {1, 2, 0}, {2, 0, 1}, {0, 2, 1}, {2, 1, 0}, {1, 0, 2}}
+
+// SymmetryCount is the number of cube symmetries.
+const SymmetryCount = 48
+
+// SymmetryFromIndex returns symmetry i (0..47). Index 0 is the identity; i/8 selects
+// the axis permutation and bit k of i%8 flips output axis k.
+func SymmetryFromIndex(i int) (Symmetry, error) {
+	if i < 0 || i >= SymmetryCount {
+		return Symmetry{}, fmt.Errorf("symmetry index out of range: %d", i)
+	}
+	s := Symmetry{Perm: axisPerms[i/8]}
+	for k := range 3 {
+		s.Flip[k] = (i%8)&(1<<k) != 0
+	}
+	return s, nil
+}
+
+// Index returns the symmetry's index for SymmetryFromIndex.
+func (s Symmetry) Index() int {
+	p := 0
+	for i, perm := range axisPerms {
+		if perm == s.Perm {
+			p = i
+		}
+	}
+	f := 0
+	for k := range 3 {
+		if s.Flip[k] {
+			f |= 1 << k
+		}
+	}
+	return p*8 + f
+}
+
+// Proper reports whether s is a rotation (no net reflection).
+func (s Symmetry) Proper() bool {
+	even := s.Index()/8 < 3
+	flips := 0
+	for _, f := range s.Flip {
+		if f {
+			flips++
+		}
+	}
+	return even == (flips%2 == 0)
+}
+
+// Symmetries returns all 48 symmetries in index order, or only the 24 rotations when
+// properOnly is set.
+func Symmetries(properOnly bool) []Symmetry {
+	out := make([]Symmetry, 0, SymmetryCount)
+	for i := range SymmetryCount {
+		s, _ := SymmetryFromIndex(i)
+		if !properOnly || s.Proper() {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Size returns the extent of a box of the given size after applying s.
+func (s Symmetry) Size(size [3]int) [3]int {
+	return [3]int{size[s.Perm[0]], size[s.Perm[1]], size[s.Perm[2]]}
+}
+
+// Apply maps cell p of a box of the given size to its cell in the transformed box.
+func (s Symmetry) Apply(p, size [3]int) [3]int {
+	var out [3]int
+	for i := range 3 {
+		a := s.Perm[i]
+		out[i] = p[a]
+		if s.Flip[i] {
+			out[i] = size[a] - 1 - p[a]
+		}
+	}
+	return out
+}
+
+// Inverse returns the symmetry that undoes s.
+func (s Symmetry) Inverse() Symmetry {
+	var inv Symmetry
+	for i := range 3 {
+		a := s.Perm[i]
+		inv.Perm[a] = uint8(i)
+		inv.Flip[a] = s.Flip[i]
+	}
+	return inv
+}
+
+// Transform returns a copy of c with s applied; the anchor moves with the contents.
+func (c *Clipboard) Transform(s Symmetry) *Clipboard {
+	return c.remap(s.Size(c.Size), func(p [3]int) [3]int { return s.Apply(p, c.Size) })
+}
+
+// Transform returns a copy of g with s applied about the chunk's center.
+func (g *VoxelGrid) Transform(s Symmetry) *VoxelGrid {
+	size := [3]int{Width, Height, Depth}
+	out := new(VoxelGrid)
+	for v := range g.Occupied() {
+		p := s.Apply([3]int{v.X, v.Y, v.Z}, size)
+		out[p[1]][p[0]][p[2]] = v.Color
+	}
+	return out
+}
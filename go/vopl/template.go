@@ -0,0 +1,174 @@
+package vopl
+
+import (
+	"bytes"
+	"slices"
+)
+
+// TemplateOptions controls MatchTemplate.
+type TemplateOptions struct {
+	// Wildcard, when non-zero, is a palette index that matches any cell, empty or not.
+	Wildcard uint8
+	// AirIsWildcard makes empty template cells match anything; otherwise they only
+	// match empty cells.
+	AirIsWildcard bool
+	// Rotations also tries the 24 rotations of the template.
+	Rotations bool
+	// Mirrors also tries mirrored orientations (all 48 symmetries together with Rotations,
+	// otherwise the identity and its three axis reflections).
+	Mirrors bool
+}
+
+// TemplateMatch is one occurrence of a template.
+type TemplateMatch struct {
+	// Offset is the cell where the oriented template's minimum corner lies, in chunk or
+	// world coordinates (see MatchTemplate and MatchTemplateWorld).
+	Offset [3]int `json:"offset"`
+	// Size is the extent of the oriented template.
+	Size [3]int `json:"size"`
+	// Symmetry is the index (see SymmetryFromIndex) of the orientation that matched.
+	Symmetry int `json:"symmetry"`
+}
+
+type orientedTemplate struct {
+	sym   int
+	clip  *Clipboard
+	cells []templateCell // the cells that constrain a match, most selective first
+}
+
+type templateCell struct {
+	p     [3]int
+	color uint8
+}
+
+// templateOrientations returns the distinct orientations of tmpl to try under opts
+// (symmetric templates yield fewer), each paired with its symmetry index.
+func templateOrientations(tmpl *Clipboard, opts TemplateOptions) []orientedTemplate {
+	var syms []Symmetry
+	switch {
+	case opts.Rotations && opts.Mirrors:
+		syms = Symmetries(false)
+	case opts.Rotations:
+		syms = Symmetries(true)
+	case opts.Mirrors:
+		for _, i := range []int{0, 1, 2, 4} {
+			s, _ := SymmetryFromIndex(i)
+			syms = append(syms, s)
+		}
+	default:
+		syms = []Symmetry{{Perm: axisPerms[0]}}
+	}
+	var out []orientedTemplate
+next:
+	for _, s := range syms {
+		c := tmpl.Transform(s)
+		for _, o := range out {
+			if o.clip.Size == c.Size && bytes.Equal(o.clip.Data, c.Data) {
+				continue next
+			}
+		}
+		ot := orientedTemplate{sym: s.Index(), clip: c}
+		var empty []templateCell
+		for z := range c.Size[2] {
+			for y := range c.Size[1] {
+				for x := range c.Size[0] {
+					col := c.Get(x, y, z)
+					switch {
+					case opts.Wildcard != 0 && col == opts.Wildcard:
+					case col == 0 && opts.AirIsWildcard:
+					case col == 0:
+						empty = append(empty, templateCell{[3]int{x, y, z}, 0})
+					default:
+						ot.cells = append(ot.cells, templateCell{[3]int{x, y, z}, col})
+					}
+				}
+			}
+		}
+		// solid cells reject most candidate offsets, so test them first
+		ot.cells = append(ot.cells, empty...)
+		out = append(out, ot)
+	}
+	return out
+}
+
+// MatchTemplate finds every placement of tmpl (in any orientation allowed by opts)
+// that lies entirely inside g and whose constrained cells equal g's. Matches are
+// ordered by orientation, then by offset y, x, z. Templates made only of wildcards
+// match nothing.
+func MatchTemplate(g *VoxelGrid, tmpl *Clipboard, opts TemplateOptions) []TemplateMatch {
+	var out []TemplateMatch
+	for _, ot := range templateOrientations(tmpl, opts) {
+		s := ot.clip.Size
+		out = ot.match(g, [3]int{}, [3]int{Width - s[0] + 1, Height - s[1] + 1, Depth - s[2] + 1}, out)
+	}
+	return out
+}
+
+// MatchTemplateWorld is MatchTemplate over a whole world: placements may cross chunk
+// borders and Offset is in world coordinates. Every placement is found once, while
+// scanning the chunk that holds its minimum corner; chunks are scanned in Coords order,
+// including missing chunks close enough below an existing one for a placement to reach
+// into it. Within a chunk, matches are ordered as in MatchTemplate.
+func MatchTemplateWorld(w *World, tmpl *Clipboard, opts TemplateOptions) []TemplateMatch {
+	orients := templateOrientations(tmpl, opts)
+	var size [3]int
+	for _, ot := range orients {
+		for i := range 3 {
+			size[i] = max(size[i], ot.clip.Size[i])
+		}
+	}
+	// a placement whose corner lies in chunk c reaches at most this many chunks past c
+	var reach [3]int
+	for i, n := range [3]int{Width, Height, Depth} {
+		reach[i] = (size[i] + n - 2) / n
+	}
+	corners := map[ChunkCoord]bool{}
+	for _, c := range w.Coords() {
+		for dy := 0; dy <= reach[1]; dy++ {
+			for dx := 0; dx <= reach[0]; dx++ {
+				for dz := 0; dz <= reach[2]; dz++ {
+					if cc := (ChunkCoord{c[0] - dx, c[1] - dy, c[2] - dz}); cc.Valid() {
+						corners[cc] = true
+					}
+				}
+			}
+		}
+	}
+	order := make([]ChunkCoord, 0, len(corners))
+	for c := range corners {
+		order = append(order, c)
+	}
+	slices.SortFunc(order, func(a, b ChunkCoord) int { return slices.Compare(a[:], b[:]) })
+
+	var out []TemplateMatch
+	for _, c := range order {
+		lo := c.Origin()
+		hi := [3]int{lo[0] + Width, lo[1] + Height, lo[2] + Depth}
+		for _, ot := range orients {
+			out = ot.match(w, lo, hi, out)
+		}
+	}
+	return out
+}
+
+// match appends the placements of ot in src whose minimum corner lies in the half-open
+// box [lo, hi), ordered by y, x, z.
+func (ot orientedTemplate) match(src Voxels, lo, hi [3]int, out []TemplateMatch) []TemplateMatch {
+	if len(ot.cells) == 0 {
+		return out
+	}
+	for oy := lo[1]; oy < hi[1]; oy++ {
+		for ox := lo[0]; ox < hi[0]; ox++ {
+		offsets:
+			for oz := lo[2]; oz < hi[2]; oz++ {
+				for _, c := range ot.cells {
+					if src.Get(ox+c.p[0], oy+c.p[1], oz+c.p[2]) != c.color {
+						continue offsets
+					}
+				}
+				out = append(out, TemplateMatch{Offset: [3]int{ox, oy, oz}, Size: ot.clip.Size, Symmetry: ot.sym})
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,179 @@
+package vopl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VoxelWriter accepts point writes. *VoxelGrid writes local chunk coordinates (ignoring
+// cells outside the chunk); ChunkMap writes world coordinates and spans chunk borders.
+type VoxelWriter interface {
+	Set(x, y, z int, color uint8) bool
+}
+
+// TextOptions controls RenderText.
+type TextOptions struct {
+	// Right and Up are the unit axis directions in which glyph columns and rows advance.
+	// Zero values mean +X and +Y (text facing +Z).
+	Right, Up [3]int
+	// Depth is the extrusion, in voxels, along Right×Up; values below 1 mean 1.
+	Depth int
+	// Color is the palette index written for glyph pixels; 0 carves the text out.
+	Color uint8
+	// Scale is the size in voxels of one font pixel; values below 1 mean 1.
+	Scale int
+	// Wrap, when positive, is the maximum line width in voxels: lines are broken at
+	// spaces (or mid-word when a word alone is too long).
+	Wrap int
+}
+
+func (o TextOptions) normalized() (TextOptions, error) {
+	if o.Right == ([3]int{}) && o.Up == ([3]int{}) {
+		o.Right, o.Up = [3]int{1, 0, 0}, [3]int{0, 1, 0}
+	}
+	if !unitAxis(o.Right) || !unitAxis(o.Up) || o.Right[0]*o.Up[0]+o.Right[1]*o.Up[1]+o.Right[2]*o.Up[2] != 0 {
+		return o, fmt.Errorf("text orientation must be two perpendicular axes, got right=%v up=%v", o.Right, o.Up)
+	}
+	o.Depth = max(o.Depth, 1)
+	o.Scale = max(o.Scale, 1)
+	return o, nil
+}
+
+func unitAxis(v [3]int) bool {
+	return abs(v[0])+abs(v[1])+abs(v[2]) == 1
+}
+
+// ParseTextPlane parses an orientation "right,up" of signed axis names, e.g. "x,y"
+// (upright, facing +Z), "-z,y" (upright, facing +X) or "x,-z" (lying flat, facing +Y).
+func ParseTextPlane(s string) (right, up [3]int, err error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return right, up, fmt.Errorf("expected right,up axes (e.g. x,y): %q", s)
+	}
+	var dirs [2][3]int
+	for i, p := range parts {
+		p = strings.TrimSpace(p)
+		sign := 1
+		if strings.HasPrefix(p, "-") || strings.HasPrefix(p, "+") {
+			if p[0] == '-' {
+				sign = -1
+			}
+			p = p[1:]
+		}
+		a, err := ParseAxis(p)
+		if err != nil {
+			return right, up, err
+		}
+		dirs[i][a] = sign
+	}
+	if dirs[0] == dirs[1] || dirs[0] == [3]int{-dirs[1][0], -dirs[1][1], -dirs[1][2]} {
+		return right, up, fmt.Errorf("text axes must be perpendicular: %q", s)
+	}
+	return dirs[0], dirs[1], nil
+}
+
+// LayoutText splits s into the lines RenderText draws: at '\n' and, when opts.Wrap is
+// positive, wherever a line would exceed opts.Wrap voxels. Characters outside printable
+// ASCII are replaced by '?'.
+func LayoutText(s string, opts TextOptions) []string {
+	scale := max(opts.Scale, 1)
+	var out []string
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.Map(func(r rune) rune {
+			if r < 0x20 || r > 0x7E {
+				return '?'
+			}
+			return r
+		}, line)
+		if opts.Wrap <= 0 {
+			out = append(out, line)
+			continue
+		}
+		limit := max((opts.Wrap/scale+1)/GlyphAdvance, 1)
+		out = append(out, wrapLine(line, limit)...)
+	}
+	return out
+}
+
+// wrapLine greedily breaks line into pieces of at most limit characters.
+func wrapLine(line string, limit int) []string {
+	var out []string
+	cur := ""
+	for _, word := range strings.Fields(line) {
+		for len(word) > limit {
+			if cur != "" {
+				out = append(out, cur)
+				cur = ""
+			}
+			out = append(out, word[:limit])
+			word = word[limit:]
+		}
+		switch {
+		case cur == "":
+			cur = word
+		case len(cur)+1+len(word) <= limit:
+			cur += " " + word
+		default:
+			out = append(out, cur)
+			cur = word
+		}
+	}
+	return append(out, cur)
+}
+
+// TextSize returns the extent in voxels of the rendered text along Right (w) and Up (h).
+func TextSize(s string, opts TextOptions) (w, h int) {
+	scale := max(opts.Scale, 1)
+	lines := LayoutText(s, opts)
+	for _, l := range lines {
+		if len(l) > 0 {
+			w = max(w, (len(l)*GlyphAdvance-1)*scale)
+		}
+	}
+	return w, (len(lines)*LineAdvance - 1) * scale
+}
+
+// RenderText draws s with the built-in 5x7 font. origin is the bottom-left-front cell
+// of the text block: columns advance along Right, the first line is on top and later
+// lines stack towards -Up, and pixels extrude Depth cells along Right×Up. It returns
+// the number of cells written.
+func RenderText(dst VoxelWriter, origin [3]int, s string, opts TextOptions) (int, error) {
+	opts, err := opts.normalized()
+	if err != nil {
+		return 0, err
+	}
+	r, u := opts.Right, opts.Up
+	n := [3]int{r[1]*u[2] - r[2]*u[1], r[2]*u[0] - r[0]*u[2], r[0]*u[1] - r[1]*u[0]}
+	lines := LayoutText(s, opts)
+	_, h := TextSize(s, opts)
+	written := 0
+	for li, line := range lines {
+		for ci, ch := range []byte(line) {
+			cols := glyph(rune(ch))
+			for gx, bits := range cols {
+				for gy := range GlyphHeight {
+					if bits&(1<<gy) == 0 {
+						continue
+					}
+					// font pixel position in the block, counted from the bottom-left
+					px := ci*GlyphAdvance + gx
+					py := h/opts.Scale - 1 - (li*LineAdvance + gy)
+					for sx := range opts.Scale {
+						for sy := range opts.Scale {
+							a, b := px*opts.Scale+sx, py*opts.Scale+sy
+							for d := range opts.Depth {
+								x := origin[0] + a*r[0] + b*u[0] + d*n[0]
+								y := origin[1] + a*r[1] + b*u[1] + d*n[1]
+								z := origin[2] + a*r[2] + b*u[2] + d*n[2]
+								if dst.Set(x, y, z, opts.Color) {
+									written++
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return written, nil
+}
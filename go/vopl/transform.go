@@ -0,0 +1,140 @@
+package vopl
+
+import "fmt"
+
+// Axis selects one of the three grid axes.
+type Axis uint8
+
+const (
+	AxisX Axis = 0
+	AxisY Axis = 1
+	AxisZ Axis = 2
+)
+
+func (a Axis) String() string {
+	switch a {
+	case AxisX:
+		return "x"
+	case AxisY:
+		return "y"
+	case AxisZ:
+		return "z"
+	}
+	return fmt.Sprintf("Axis(%d)", uint8(a))
+}
+
+// ParseAxis accepts "x", "y" or "z" (case-insensitive).
+func ParseAxis(s string) (Axis, error) {
+	switch s {
+	case "x", "X":
+		return AxisX, nil
+	case "y", "Y":
+		return AxisY, nil
+	case "z", "Z":
+		return AxisZ, nil
+	}
+	return 0, fmt.Errorf("invalid axis: %q", s)
+}
+
+// ShiftMode controls what happens to voxels pushed past the grid edge by Shift.
+type ShiftMode uint8
+
+const (
+	// ShiftClip discards voxels that leave the grid and fills vacated cells with 0.
+	ShiftClip ShiftMode = 0
+	// ShiftWrap re-enters voxels on the opposite side (toroidal shift).
+	ShiftWrap ShiftMode = 1
+)
+
+// Rotate90 returns a copy of g rotated by quarterTurns * 90° about axis.
+// Positive turns are counter-clockwise when looking from +axis towards the origin
+// (right-handed): about Y, +X moves to -Z; about X, +Y moves to +Z; about Z, +X moves to +Y.
+// Negative turns rotate the other way. The grid is a cube so every rotation
+// maps it onto itself.
+func (g *VoxelGrid) Rotate90(axis Axis, quarterTurns int) *VoxelGrid {
+	turns := ((quarterTurns % 4) + 4) % 4
+	out := *g
+	for range turns {
+		out = rotateOnce(&out, axis)
+	}
+	return &out
+}
+
+func rotateOnce(g *VoxelGrid, axis Axis) VoxelGrid {
+	var out VoxelGrid
+	for y := range Height {
+		for x := range Width {
+			for z := range Depth {
+				c := g[y][x][z]
+				if c == 0 {
+					continue
+				}
+				nx, ny, nz := x, y, z
+				switch axis {
+				case AxisX:
+					ny, nz = Depth-1-z, y
+				case AxisY:
+					nx, nz = z, Width-1-x
+				case AxisZ:
+					nx, ny = Height-1-y, x
+				}
+				out[ny][nx][nz] = c
+			}
+		}
+	}
+	return out
+}
+
+// Mirror returns a copy of g reflected across the plane perpendicular to axis
+// through the grid center (e.g. AxisX maps x to Width-1-x).
+func (g *VoxelGrid) Mirror(axis Axis) *VoxelGrid {
+	var out VoxelGrid
+	for y := range Height {
+		for x := range Width {
+			for z := range Depth {
+				nx, ny, nz := x, y, z
+				switch axis {
+				case AxisX:
+					nx = Width - 1 - x
+				case AxisY:
+					ny = Height - 1 - y
+				case AxisZ:
+					nz = Depth - 1 - z
+				}
+				out[ny][nx][nz] = g[y][x][z]
+			}
+		}
+	}
+	return &out
+}
+
+// Shift returns a copy of g with its contents translated by (dx,dy,dz).
+func (g *VoxelGrid) Shift(dx, dy, dz int, mode ShiftMode) *VoxelGrid {
+	var out VoxelGrid
+	for y := range Height {
+		for x := range Width {
+			for z := range Depth {
+				c := g[y][x][z]
+				if c == 0 {
+					continue
+				}
+				nx, ny, nz := x+dx, y+dy, z+dz
+				if mode == ShiftWrap {
+					nx = wrapIndex(nx, Width)
+					ny = wrapIndex(ny, Height)
+					nz = wrapIndex(nz, Depth)
+				}
+				out.Set(nx, ny, nz, c)
+			}
+		}
+	}
+	return &out
+}
+
+func wrapIndex(v, size int) int {
+	v %= size
+	if v < 0 {
+		v += size
+	}
+	return v
+}
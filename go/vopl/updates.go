@@ -0,0 +1,96 @@
+package vopl
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Updates is the updates JSON document exchanged with clients:
+//
+//	{ "<chunkId>": { "<index>": <color>, ... }, ... }
+//
+// where index is LinearIndex(x,y,z) = x + y*W + z*W*H.
+type Updates map[string]map[string]int
+
+// VoxelChange is a single cell that differs between two grids.
+type VoxelChange struct {
+	X, Y, Z  int
+	From, To uint8
+}
+
+// Index returns the change position as a LinearIndex.
+func (c VoxelChange) Index() int { return LinearIndex(c.X, c.Y, c.Z) }
+
+// Diff returns every cell whose value differs between a and b, in ascending
+// LinearIndex order. Applying the To values to a yields b.
+func Diff(a, b *VoxelGrid) []VoxelChange {
+	var out []VoxelChange
+	for z := range Depth {
+		for y := range Height {
+			for x := range Width {
+				if ca, cb := a[y][x][z], b[y][x][z]; ca != cb {
+					out = append(out, VoxelChange{X: x, Y: y, Z: z, From: ca, To: cb})
+				}
+			}
+		}
+	}
+	return out
+}
+
+// ParseUpdates decodes an updates JSON document.
+func ParseUpdates(data []byte) (Updates, error) {
+	var u Updates
+	if err := json.Unmarshal(data, &u); err != nil {
+		return nil, fmt.Errorf("invalid updates JSON: %w", err)
+	}
+	return u, nil
+}
+
+// Add records changes for chunkID. Later changes to the same cell overwrite earlier ones.
+func (u Updates) Add(chunkID string, changes []VoxelChange) {
+	if len(changes) == 0 {
+		return
+	}
+	m := u[chunkID]
+	if m == nil {
+		m = make(map[string]int, len(changes))
+		u[chunkID] = m
+	}
+	for _, c := range changes {
+		m[strconv.Itoa(c.Index())] = int(c.To)
+	}
+}
+
+// DiffUpdates returns an updates document that turns a into b for chunkID.
+// The document is empty when the grids are identical.
+func DiffUpdates(chunkID string, a, b *VoxelGrid) Updates {
+	u := Updates{}
+	u.Add(chunkID, Diff(a, b))
+	return u
+}
+
+// Apply writes the updates for a single chunk into grid. Out-of-range indices are
+// ignored and colors are clamped to [0,255]. It returns the number of cells written.
+func (u Updates) Apply(chunkID string, grid *VoxelGrid) (int, error) {
+	n := 0
+	for idxStr, col := range u[chunkID] {
+		idx, err := strconv.Atoi(idxStr)
+		if err != nil {
+			return n, fmt.Errorf("invalid voxel index '%s': %w", idxStr, err)
+		}
+		if idx < 0 || idx >= Width*Height*Depth {
+			continue
+		}
+		col = max(0, min(col, 255))
+		x, y, z := XYZFromLinearIndex(idx)
+		grid.Set(x, y, z, uint8(col))
+		n++
+	}
+	return n, nil
+}
+
+// Marshal encodes the document as JSON. Object keys are emitted in sorted order.
+func (u Updates) Marshal() ([]byte, error) {
+	return json.Marshal(u)
+}
@@ -0,0 +1,35 @@
+package vopl
+
+import "fmt"
+
+// Upscale enlarges g by an integer factor with nearest-neighbour sampling and splits
+// the result into chunks. Source cell (x,y,z) becomes a factor³ block at world
+// origin.Origin() + factor*(x,y,z). Only non-empty chunks are returned, keyed by
+// chunk coordinate starting at origin.
+func Upscale(g *VoxelGrid, factor int, origin ChunkCoord) (ChunkMap, error) {
+	if factor < 1 {
+		return nil, fmt.Errorf("upscale factor must be >= 1 (got %d)", factor)
+	}
+	last := ChunkCoord{origin[0] + factor - 1, origin[1] + factor - 1, origin[2] + factor - 1}
+	if !origin.Valid() || !last.Valid() {
+		return nil, fmt.Errorf("chunk coordinates out of range: %v..%v", origin, last)
+	}
+	out := make(ChunkMap)
+	base := origin.Origin()
+	for v := range g.Occupied() {
+		for dy := range factor {
+			for dx := range factor {
+				for dz := range factor {
+					c, local := ChunkOf(base[0]+v.X*factor+dx, base[1]+v.Y*factor+dy, base[2]+v.Z*factor+dz)
+					chunk := out[c]
+					if chunk == nil {
+						chunk = new(VoxelGrid)
+						out[c] = chunk
+					}
+					chunk[local[1]][local[0]][local[2]] = v.Color
+				}
+			}
+		}
+	}
+	return out, nil
+}
@@ -3,4 +3,6 @@ package vopl
 type Vertex struct {
 	Position [3]float32
 	Color    uint8
+	// Light is the baked light level (0..MaxLight); only meaningful in a Lit mesh.
+	Light uint8
 }
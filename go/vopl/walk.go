@@ -0,0 +1,272 @@
+package vopl
+
+import (
+	"container/heap"
+	"fmt"
+	"slices"
+)
+
+// WalkOptions describes the agent that walks on voxels.
+type WalkOptions struct {
+	// Headroom is the number of empty cells the agent needs from its feet up (>= 1).
+	Headroom int
+	// StepUp is the highest ledge the agent climbs in one move.
+	StepUp int
+	// Drop is the deepest fall the agent takes in one move.
+	Drop int
+}
+
+// DefaultWalkOptions describes a two-voxel-tall agent that climbs one voxel and
+// drops up to three.
+func DefaultWalkOptions() WalkOptions { return WalkOptions{Headroom: 2, StepUp: 1, Drop: 3} }
+
+func (o WalkOptions) validate() error {
+	if o.Headroom < 1 || o.StepUp < 0 || o.Drop < 0 {
+		return fmt.Errorf("invalid walk options: headroom must be >= 1, step and drop >= 0 (got %d, %d, %d)", o.Headroom, o.StepUp, o.Drop)
+	}
+	return nil
+}
+
+// walkDirs are the horizontal moves, as (dx, dz).
+var walkDirs = [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+
+// Walkable reports whether an agent can stand with its feet in cell (x,y,z): the cell
+// below is solid and the headroom cells from (x,y,z) up are empty.
+func Walkable(src Voxels, x, y, z, headroom int) bool {
+	if src.Get(x, y-1, z) == 0 {
+		return false
+	}
+	return clearColumn(src, x, y, z, headroom)
+}
+
+// clearColumn reports whether the n cells from (x,y,z) up are empty.
+func clearColumn(src Voxels, x, y, z, n int) bool {
+	for i := range n {
+		if src.Get(x, y+i, z) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// walkStep returns the feet height reached by moving from the walkable cell (x,y,z)
+// one cell along (dx,dz). When the agent fits in the next column at its current
+// height it walks on or falls up to Drop cells to the first floor; otherwise it climbs
+// onto the lowest walkable ledge up to StepUp cells higher, provided its head clears
+// the cells above its current column.
+func walkStep(src Voxels, x, y, z, dx, dz int, opts WalkOptions) (int, bool) {
+	nx, nz := x+dx, z+dz
+	if clearColumn(src, nx, y, nz, opts.Headroom) {
+		for ny := y; ny >= y-opts.Drop; ny-- {
+			if src.Get(nx, ny-1, nz) != 0 {
+				return ny, true
+			}
+		}
+		return 0, false
+	}
+	for ny := y + 1; ny <= y+opts.StepUp; ny++ {
+		if src.Get(x, ny+opts.Headroom-1, z) != 0 {
+			break
+		}
+		if Walkable(src, nx, ny, nz, opts.Headroom) {
+			return ny, true
+		}
+	}
+	return 0, false
+}
+
+func (b Box) contains(p [3]int) bool {
+	for i := range 3 {
+		if p[i] < b.Min[i] || p[i] >= b.Max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// WalkableCells returns the walkable feet cells inside b, ordered by y, then x, then z.
+// Cells above and below b are still read for floors and headroom.
+func WalkableCells(src Voxels, b Box, headroom int) [][3]int {
+	var out [][3]int
+	for y := b.Min[1]; y < b.Max[1]; y++ {
+		for x := b.Min[0]; x < b.Max[0]; x++ {
+			for z := b.Min[2]; z < b.Max[2]; z++ {
+				if Walkable(src, x, y, z, headroom) {
+					out = append(out, [3]int{x, y, z})
+				}
+			}
+		}
+	}
+	return out
+}
+
+// FindPath runs A* over the walkable cells of b from start to goal, moving one cell
+// along x or z per step (see WalkOptions for climbing and falling). Every move costs
+// one. It returns the feet cells of a shortest path, start and goal included, and
+// false when goal cannot be reached inside b.
+func FindPath(src Voxels, b Box, start, goal [3]int, opts WalkOptions) ([][3]int, bool, error) {
+	if err := opts.validate(); err != nil {
+		return nil, false, err
+	}
+	for _, p := range [2][3]int{start, goal} {
+		if !b.contains(p) {
+			return nil, false, fmt.Errorf("%v is outside the search region", p)
+		}
+		if !Walkable(src, p[0], p[1], p[2], opts.Headroom) {
+			return nil, false, fmt.Errorf("%v is not walkable", p)
+		}
+	}
+	h := func(p [3]int) int { return abs(goal[0]-p[0]) + abs(goal[2]-p[2]) }
+	cost := map[[3]int]int{start: 0}
+	from := map[[3]int][3]int{}
+	open := &pathQueue{}
+	heap.Push(open, pathNode{p: start, f: h(start)})
+	for open.Len() > 0 {
+		n := heap.Pop(open).(pathNode)
+		if n.p == goal {
+			path := [][3]int{goal}
+			for p := goal; p != start; {
+				p = from[p]
+				path = append(path, p)
+			}
+			slices.Reverse(path)
+			return path, true, nil
+		}
+		if n.g > cost[n.p] {
+			continue // stale entry
+		}
+		for _, d := range walkDirs {
+			ny, ok := walkStep(src, n.p[0], n.p[1], n.p[2], d[0], d[1], opts)
+			next := [3]int{n.p[0] + d[0], ny, n.p[2] + d[1]}
+			if !ok || !b.contains(next) {
+				continue
+			}
+			if c, seen := cost[next]; seen && c <= n.g+1 {
+				continue
+			}
+			cost[next] = n.g + 1
+			from[next] = n.p
+			open.seq++
+			heap.Push(open, pathNode{p: next, g: n.g + 1, f: n.g + 1 + h(next), seq: open.seq})
+		}
+	}
+	return nil, false, nil
+}
+
+type pathNode struct {
+	p    [3]int
+	g, f int
+	seq  int
+}
+
+// pathQueue is a min-heap on f, then the most recently pushed node, which keeps A*
+// deterministic and favours extending the current path on ties.
+type pathQueue struct {
+	nodes []pathNode
+	seq   int
+}
+
+func (q *pathQueue) Len() int { return len(q.nodes) }
+func (q *pathQueue) Less(i, j int) bool {
+	if q.nodes[i].f != q.nodes[j].f {
+		return q.nodes[i].f < q.nodes[j].f
+	}
+	return q.nodes[i].seq > q.nodes[j].seq
+}
+func (q *pathQueue) Swap(i, j int) { q.nodes[i], q.nodes[j] = q.nodes[j], q.nodes[i] }
+func (q *pathQueue) Push(x any)    { q.nodes = append(q.nodes, x.(pathNode)) }
+func (q *pathQueue) Pop() any {
+	n := q.nodes[len(q.nodes)-1]
+	q.nodes = q.nodes[:len(q.nodes)-1]
+	return n
+}
+
+// NavRegion is a rectangle of walkable feet cells at one height, a polygon of a
+// navigation mesh.
+type NavRegion struct {
+	ID int `json:"id"`
+	// Box spans the region's feet cells; its y extent is always one.
+	Box
+	// Links lists the regions reachable from this one in a single move, ascending.
+	// Drops make some links one-way.
+	Links []int `json:"links"`
+}
+
+// BuildNavRegions covers the walkable cells of b with rectangles, grown greedily along
+// z then x at each height, and links the rectangles an agent can move between. Regions
+// are ordered by the y, x, z of their minimum corner and ID is their index.
+func BuildNavRegions(src Voxels, b Box, opts WalkOptions) ([]NavRegion, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	size := b.Size()
+	if size[0] <= 0 || size[1] <= 0 || size[2] <= 0 {
+		return nil, fmt.Errorf("empty region: %v", size)
+	}
+	idx := func(x, y, z int) int {
+		return (x - b.Min[0]) + size[0]*((z-b.Min[2])+size[2]*(y-b.Min[1]))
+	}
+	// region holds the region id of every walkable cell, -1 for unassigned ones and
+	// -2 for cells that are not walkable.
+	region := make([]int, size[0]*size[1]*size[2])
+	for i := range region {
+		region[i] = -2
+	}
+	for _, p := range WalkableCells(src, b, opts.Headroom) {
+		region[idx(p[0], p[1], p[2])] = -1
+	}
+	free := func(x, y, z int) bool {
+		return x < b.Max[0] && z < b.Max[2] && region[idx(x, y, z)] == -1
+	}
+
+	var out []NavRegion
+	for y := b.Min[1]; y < b.Max[1]; y++ {
+		for x := b.Min[0]; x < b.Max[0]; x++ {
+			for z := b.Min[2]; z < b.Max[2]; z++ {
+				if !free(x, y, z) {
+					continue
+				}
+				z1 := z + 1
+				for free(x, y, z1) {
+					z1++
+				}
+				x1 := x + 1
+			grow:
+				for ; x1 < b.Max[0]; x1++ {
+					for zz := z; zz < z1; zz++ {
+						if !free(x1, y, zz) {
+							break grow
+						}
+					}
+				}
+				id := len(out)
+				for xx := x; xx < x1; xx++ {
+					for zz := z; zz < z1; zz++ {
+						region[idx(xx, y, zz)] = id
+					}
+				}
+				out = append(out, NavRegion{ID: id, Box: Box{Min: [3]int{x, y, z}, Max: [3]int{x1, y + 1, z1}}, Links: []int{}})
+			}
+		}
+	}
+
+	for i := range out {
+		r := &out[i]
+		for x := r.Min[0]; x < r.Max[0]; x++ {
+			for z := r.Min[2]; z < r.Max[2]; z++ {
+				for _, d := range walkDirs {
+					ny, ok := walkStep(src, x, r.Min[1], z, d[0], d[1], opts)
+					next := [3]int{x + d[0], ny, z + d[1]}
+					if !ok || !b.contains(next) {
+						continue
+					}
+					if to := region[idx(next[0], next[1], next[2])]; to != r.ID && !slices.Contains(r.Links, to) {
+						r.Links = append(r.Links, to)
+					}
+				}
+			}
+		}
+		slices.Sort(r.Links)
+	}
+	return out, nil
+}
@@ -0,0 +1,358 @@
+package vopl
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// World is a sparse set of chunks addressed in world coordinates. Chunks are created
+// when a voxel is first written into them and dropped as soon as they become empty,
+// so a World never holds an empty chunk.
+type World struct {
+	chunks ChunkMap
+	// removed lists chunks dropped since the world was loaded or saved, so Save can
+	// delete their files from a directory.
+	removed map[ChunkCoord]bool
+	// dirty lists chunks written since the world was loaded or saved. When saving back
+	// to dir, the directory whose files hold every other chunk, only these are written.
+	dirty map[ChunkCoord]bool
+	dir   string
+	// names holds the file or entry name each chunk was loaded under, so Save writes it
+	// back under that name; other chunks are named ChunkCoord.FileName.
+	names map[ChunkCoord]string
+	// format is how the .voplpack the world was loaded from is encoded, nil otherwise.
+	format *packFormat
+}
+
+// packFormat is the common header, layout and compression of a .voplpack.
+type packFormat struct {
+	header VOPLHeader
+	layout PackLayout
+	comp   PackCompression
+}
+
+// defaultPackFormat is used for worlds not loaded from a pack: a zlib-compressed raw
+// (v1) pack of 6 bpp chunks.
+var defaultPackFormat = packFormat{
+	header: VOPLHeader{Ver: 3, BPP: 6, W: Width, H: Height, D: Depth, Pal: 64},
+	layout: LayoutRaw,
+	comp:   PackCompZlib,
+}
+
+// NewWorld returns an empty world.
+func NewWorld() *World {
+	return &World{
+		chunks:  ChunkMap{},
+		removed: map[ChunkCoord]bool{},
+		dirty:   map[ChunkCoord]bool{},
+		names:   map[ChunkCoord]string{},
+	}
+}
+
+// name returns the file or pack entry name of the chunk at c.
+func (w *World) name(c ChunkCoord) string {
+	if n, ok := w.names[c]; ok {
+		return n
+	}
+	return c.FileName()
+}
+
+// Get returns the palette index at world position (x,y,z), or 0 where no chunk exists.
+func (w *World) Get(x, y, z int) uint8 { return w.chunks.Get(x, y, z) }
+
+// Set writes color at world position (x,y,z), creating its chunk on the first
+// non-zero write and dropping it when the write leaves it empty. It reports false for
+// positions whose chunk coordinate is not Valid.
+func (w *World) Set(x, y, z int, color uint8) bool {
+	if !w.chunks.Set(x, y, z, color) {
+		return false
+	}
+	c, _ := ChunkOf(x, y, z)
+	g := w.chunks[c]
+	switch {
+	case g == nil:
+	case color == 0 && g.IsEmpty():
+		w.drop(c)
+	default:
+		delete(w.removed, c)
+		w.dirty[c] = true
+	}
+	return true
+}
+
+func (w *World) drop(c ChunkCoord) {
+	delete(w.chunks, c)
+	delete(w.dirty, c)
+	w.removed[c] = true
+}
+
+// Chunk returns the chunk at c, or nil if it does not exist. Writes through the
+// returned grid are visible to the world, so the chunk is saved again as if written;
+// call Compact after clearing chunks that way.
+func (w *World) Chunk(c ChunkCoord) *VoxelGrid {
+	g := w.chunks[c]
+	if g != nil {
+		w.dirty[c] = true
+	}
+	return g
+}
+
+// SetChunk stores g at c, replacing any existing chunk; a nil or empty g removes it.
+func (w *World) SetChunk(c ChunkCoord, g *VoxelGrid) error {
+	if !c.Valid() {
+		return fmt.Errorf("chunk coordinate out of range: %v", c)
+	}
+	if g == nil || g.IsEmpty() {
+		if _, ok := w.chunks[c]; ok {
+			w.drop(c)
+		}
+		return nil
+	}
+	w.chunks[c] = g
+	delete(w.removed, c)
+	w.dirty[c] = true
+	return nil
+}
+
+// Len returns the number of chunks.
+func (w *World) Len() int { return len(w.chunks) }
+
+// Coords returns the chunk coordinates in ascending x, then y, then z order.
+func (w *World) Coords() []ChunkCoord {
+	out := make([]ChunkCoord, 0, len(w.chunks))
+	for c := range w.chunks {
+		out = append(out, c)
+	}
+	slices.SortFunc(out, func(a, b ChunkCoord) int { return slices.Compare(a[:], b[:]) })
+	return out
+}
+
+// Chunks returns the underlying chunk map, for functions that take a ChunkMap. Writes
+// through it may leave empty chunks behind until Compact is called, and the next Save
+// writes every chunk since the world cannot tell which ones changed.
+func (w *World) Chunks() ChunkMap {
+	w.dir = ""
+	return w.chunks
+}
+
+// Compact drops empty chunks and returns how many were dropped.
+func (w *World) Compact() int {
+	n := 0
+	for c, g := range w.chunks {
+		if g.IsEmpty() {
+			w.drop(c)
+			n++
+		}
+	}
+	return n
+}
+
+// LoadWorld reads a directory of "<chunkId>.vopl" files (non-recursive) or a .voplpack
+// whose entries are named that way. Empty chunks are skipped; saving back to the same
+// directory deletes their files. Save keeps the names chunks were loaded under and, for
+// a pack, its header, layout and compression.
+func LoadWorld(path string) (*World, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	w := NewWorld()
+	add := func(name string, g *VoxelGrid) error {
+		c, err := ParseChunkFileName(name)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		w.names[c] = name
+		if g.IsEmpty() {
+			w.removed[c] = true
+			return nil
+		}
+		return w.SetChunk(c, g)
+	}
+	if fi.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.IsDir() || !strings.EqualFold(filepath.Ext(e.Name()), ".vopl") {
+				continue
+			}
+			g, err := LoadVoplGrid(filepath.Join(path, e.Name()))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", e.Name(), err)
+			}
+			if err := add(e.Name(), g); err != nil {
+				return nil, err
+			}
+		}
+		clear(w.dirty)
+		w.dir = filepath.Clean(path)
+		return w, nil
+	}
+	if !strings.EqualFold(filepath.Ext(path), ".voplpack") {
+		return nil, fmt.Errorf("%s: expected a directory or .voplpack", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pack, layout, comp, err := UnmarshalPackEx(data)
+	if err != nil {
+		return nil, err
+	}
+	w.format = &packFormat{header: pack.Header, layout: layout, comp: comp}
+	for i, e := range pack.Entries {
+		g, err := LoadVoplGridFromBytes(BuildVOPLFromHeaderAndPayload(pack.Header, e.Enc, e.Payload))
+		if err != nil {
+			return nil, fmt.Errorf("entry %d (%s): %w", i, e.Name, err)
+		}
+		if err := add(e.Name, g); err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// Save writes the world into a directory, or into a single .voplpack when path ends in
+// .voplpack; any other existing file or .vopl path is rejected. A pack is rewritten in
+// full, encoded like the one the world was loaded from (see LoadWorld) with its bpp
+// raised if a chunk holds colors it cannot store, or else as a zlib-compressed raw
+// pack. Saving to the directory the world was loaded from or last saved to writes only
+// the chunks changed since then; other directories get every chunk. In either case,
+// files of chunks dropped since the world was loaded or last saved are deleted.
+func (w *World) Save(path string) error {
+	if strings.EqualFold(filepath.Ext(path), ".voplpack") {
+		return w.savePack(path)
+	}
+	if fi, err := os.Stat(path); err == nil && !fi.IsDir() || strings.EqualFold(filepath.Ext(path), ".vopl") {
+		return fmt.Errorf("%s: expected a directory or .voplpack", path)
+	}
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		return err
+	}
+	all := filepath.Clean(path) != w.dir
+	for _, c := range w.Coords() {
+		if !all && !w.dirty[c] {
+			continue
+		}
+		file := filepath.Join(path, w.name(c))
+		if err := SaveVoplGrid(w.chunks[c], file); err != nil {
+			return fmt.Errorf("failed to save %s: %w", file, err)
+		}
+	}
+	for c := range w.removed {
+		if w.chunks[c] != nil {
+			continue // recreated through Chunks
+		}
+		if err := os.Remove(filepath.Join(path, w.name(c))); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	clear(w.removed)
+	clear(w.dirty)
+	w.dir = filepath.Clean(path)
+	return nil
+}
+
+func (w *World) savePack(path string) error {
+	f := defaultPackFormat
+	if w.format != nil {
+		f = *w.format
+	}
+	coords := w.Coords()
+	pack := &Pack{Header: f.header}
+	for _, c := range coords {
+		for v := range w.chunks[c].Occupied() {
+			pack.Header.BPP = max(pack.Header.BPP, uint8(bits.Len8(v.Color)))
+		}
+	}
+	for _, c := range coords {
+		b := SaveVoplGridToBytesWithBPP(w.chunks[c], pack.Header.BPP)
+		_, payload, err := ParseVOPLHeaderFromBytes(b)
+		if err != nil {
+			return fmt.Errorf("%s: %w", w.name(c), err)
+		}
+		pack.Entries = append(pack.Entries, PackEntry{Name: w.name(c), Enc: b[5], Payload: payload})
+	}
+	data, err := pack.MarshalEx(f.layout, f.comp)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return err
+	}
+	// removals are no longer tracked, so no directory is known to match the world
+	clear(w.removed)
+	clear(w.dirty)
+	w.dir = ""
+	return nil
+}
+
+// ChunkUpdate summarizes the effect of an updates document on one chunk.
+type ChunkUpdate struct {
+	ID    string
+	Coord ChunkCoord
+	// Written is the number of cells the document wrote (see Updates.Apply).
+	Written int
+	// Voxels is the number of occupied cells after the update.
+	Voxels int
+	// Created and Removed report whether the chunk was created or became empty.
+	Created, Removed bool
+}
+
+// ApplyUpdates applies every chunk of u to the world, creating chunks as needed and
+// dropping those left empty. Results are ordered by ascending chunk id. Invalid chunk
+// ids or voxel indices abort before the offending chunk is changed.
+func (w *World) ApplyUpdates(u Updates) ([]ChunkUpdate, error) {
+	type target struct {
+		id    string
+		coord ChunkCoord
+		code  uint64
+	}
+	targets := make([]target, 0, len(u))
+	for id := range u {
+		c, err := ParseChunkID(id)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target{id, c, Morton3D64(uint32(c[0]), uint32(c[1]), uint32(c[2]))})
+	}
+	slices.SortFunc(targets, func(a, b target) int {
+		return cmp.Or(cmp.Compare(a.code, b.code), cmp.Compare(a.id, b.id))
+	})
+
+	out := make([]ChunkUpdate, 0, len(targets))
+	for _, t := range targets {
+		c := t.coord
+		r := ChunkUpdate{ID: t.id, Coord: c}
+		g := new(VoxelGrid)
+		if old := w.chunks[c]; old != nil {
+			*g = *old
+		} else {
+			r.Created = true
+		}
+		n, err := u.Apply(t.id, g)
+		if err != nil {
+			return out, fmt.Errorf("chunk %s: %w", t.id, err)
+		}
+		r.Written = n
+		r.Voxels = g.CountNonZero()
+		if r.Voxels == 0 {
+			r.Removed = !r.Created
+			r.Created = false
+		}
+		if err := w.SetChunk(c, g); err != nil {
+			return out, err
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
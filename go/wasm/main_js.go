@@ -15,7 +15,16 @@ func vopl2glb(this js.Value, args []js.Value) any {
 	}
 	buf := make([]byte, args[0].Get("length").Int())
 	js.CopyBytesToGo(buf, args[0])
-	out, err := api.VOPLToGLB(buf)
+	// optional second argument { sky: 0..15, emissive: { index: level } } bakes lighting
+	var light *vopl.LightOptions
+	if len(args) > 1 && args[1].Type() == js.TypeObject {
+		opts := js.Global().Get("JSON").Call("stringify", args[1]).String()
+		var err error
+		if light, err = api.ParseLightOptionsJSON([]byte(opts)); err != nil {
+			return js.ValueOf(err.Error())
+		}
+	}
+	out, err := api.VOPLToGLBWithOptions(buf, light)
 	if err != nil {
 		return js.ValueOf(err.Error())
 	}